@@ -0,0 +1,48 @@
+package authz
+
+import (
+	"errors"
+	"net/http"
+	"todo-app/pkg/clients"
+
+	"github.com/gin-gonic/gin"
+)
+
+func errMissingPermission(permission string) error {
+	return errors.New("missing required permission: " + permission)
+}
+
+// Require builds a gin middleware that rejects the request unless the
+// authenticated requester's JWT carries the given permission. Permissions
+// are embedded in the token at login (see clients.TokenPayload), so this
+// never hits the database.
+func Require(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+		if !hasPermission(requester, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, clients.ErrInvalidRequest(errMissingPermission(permission)))
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasPermission reports whether the requester's embedded grants include
+// permission. Exported so handlers that need self-service-or-admin checks
+// (UpdateUserHandler, DeleteUserHandler) can reuse the same logic as Require.
+func HasPermission(requester clients.Requester, permission string) bool {
+	return hasPermission(requester, permission)
+}
+
+func hasPermission(requester clients.Requester, permission string) bool {
+	for _, p := range requester.GetPermissions() {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
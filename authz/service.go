@@ -0,0 +1,186 @@
+package authz
+
+import (
+	"todo-app/pkg/clients"
+
+	"github.com/google/uuid"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// Role is a named bundle of permissions a user can hold. A user may hold
+// more than one role (user_roles is a many-to-many join).
+type Role struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Permission is a single grantable action, e.g. "users:write".
+type Permission struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// RoleRepo is the persistence port for roles, permissions and their
+// many-to-many relationships (role_permissions, user_roles).
+type RoleRepo interface {
+	CreateRole(role *Role) error
+	GetRole(id uuid.UUID) (*Role, error)
+	ListRoles() ([]Role, error)
+	UpdateRole(id uuid.UUID, name string) error
+	DeleteRole(id uuid.UUID) error
+	AssignPermissions(roleID uuid.UUID, permissionNames []string) error
+	PermissionsForUser(userID uuid.UUID) ([]string, error)
+	AssignUserRole(userID, roleID uuid.UUID) error
+	RemoveUserRole(userID, roleID uuid.UUID) error
+}
+
+// TokenRevoker marks every access JWT issued for a user before now as
+// revoked; middlewareAuth rejects such a token once it is near expiry. It's
+// the same revocation hook Logout and
+// ConfirmPasswordReset use, defined locally so authz doesn't have to import
+// the user package just for this one method.
+type TokenRevoker interface {
+	MarkUserRevoked(userID uuid.UUID) error
+}
+
+// RoleService is the CRUD + lookup surface for the RBAC subsystem. Permission
+// lookups are cached in-memory (keyed by user ID) for token issuance; Require
+// itself reads the grants embedded in the JWT and does not call this service.
+// Mutating a role invalidates the whole cache so stale grants can't outlive a
+// role/permission change in newly issued tokens.
+type RoleService struct {
+	repo         RoleRepo
+	tokenRevoker TokenRevoker
+	cache        *lru.Cache[uuid.UUID, []string]
+}
+
+func NewRoleService(repo RoleRepo, tokenRevoker TokenRevoker, cacheSize int) (*RoleService, error) {
+	cache, err := lru.New[uuid.UUID, []string](cacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoleService{
+		repo:         repo,
+		tokenRevoker: tokenRevoker,
+		cache:        cache,
+	}, nil
+}
+
+func (s *RoleService) CreateRole(name string) (*Role, error) {
+	role := &Role{ID: uuid.New(), Name: name}
+
+	if err := s.repo.CreateRole(role); err != nil {
+		return nil, clients.ErrCannotCreateEntity("roles", err)
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) GetRole(id uuid.UUID) (*Role, error) {
+	role, err := s.repo.GetRole(id)
+	if err != nil {
+		return nil, clients.ErrCannotGetEntity("roles", err)
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) ListRoles() ([]Role, error) {
+	roles, err := s.repo.ListRoles()
+	if err != nil {
+		return nil, clients.ErrCannotListEntity("roles", err)
+	}
+
+	return roles, nil
+}
+
+func (s *RoleService) UpdateRole(id uuid.UUID, name string) error {
+	if err := s.repo.UpdateRole(id, name); err != nil {
+		return clients.ErrCannotUpdateEntity("roles", err)
+	}
+
+	s.cache.Purge()
+
+	return nil
+}
+
+func (s *RoleService) DeleteRole(id uuid.UUID) error {
+	if err := s.repo.DeleteRole(id); err != nil {
+		return clients.ErrCannotDeleteEntity("roles", err)
+	}
+
+	s.cache.Purge()
+
+	return nil
+}
+
+// AssignPermissions replaces the set of permissions granted to a role.
+func (s *RoleService) AssignPermissions(roleID uuid.UUID, permissionNames []string) error {
+	if err := s.repo.AssignPermissions(roleID, permissionNames); err != nil {
+		return clients.ErrCannotUpdateEntity("role_permissions", err)
+	}
+
+	s.cache.Purge()
+
+	return nil
+}
+
+// AssignUserRole grants userID the given role, populating user_roles -
+// without this, PermissionsForUser has nothing to join through and every
+// user's permission set stays permanently empty. Also marks any access JWT
+// the user is already holding as revoked, so the old permissions it carries
+// stop being honoured once middlewareAuth's near-expiry check sees it.
+func (s *RoleService) AssignUserRole(userID, roleID uuid.UUID) error {
+	if err := s.repo.AssignUserRole(userID, roleID); err != nil {
+		return clients.ErrCannotCreateEntity("user_roles", err)
+	}
+
+	s.cache.Remove(userID)
+
+	if err := s.tokenRevoker.MarkUserRevoked(userID); err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	return nil
+}
+
+// RemoveUserRole revokes a previously assigned role from userID, and
+// revokes any still-valid access JWT the user is already holding for the
+// same reason AssignUserRole does.
+func (s *RoleService) RemoveUserRole(userID, roleID uuid.UUID) error {
+	if err := s.repo.RemoveUserRole(userID, roleID); err != nil {
+		return clients.ErrCannotDeleteEntity("user_roles", err)
+	}
+
+	s.cache.Remove(userID)
+
+	if err := s.tokenRevoker.MarkUserRevoked(userID); err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	return nil
+}
+
+// PermissionsForUser returns every permission granted to a user across all
+// of their roles, serving from cache when possible.
+func (s *RoleService) PermissionsForUser(userID uuid.UUID) ([]string, error) {
+	if perms, ok := s.cache.Get(userID); ok {
+		return perms, nil
+	}
+
+	perms, err := s.repo.PermissionsForUser(userID)
+	if err != nil {
+		return nil, clients.ErrCannotListEntity("role_permissions", err)
+	}
+
+	s.cache.Add(userID, perms)
+
+	return perms, nil
+}
+
+// InvalidateUser drops a single user's cached permissions, e.g. after their
+// role assignment changes.
+func (s *RoleService) InvalidateUser(userID uuid.UUID) {
+	s.cache.Remove(userID)
+}
@@ -0,0 +1,149 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeRoleRepo struct {
+	permissionsForUser map[uuid.UUID][]string
+	permCalls          int
+}
+
+func newFakeRoleRepo() *fakeRoleRepo {
+	return &fakeRoleRepo{permissionsForUser: map[uuid.UUID][]string{}}
+}
+
+func (r *fakeRoleRepo) CreateRole(role *Role) error                { return nil }
+func (r *fakeRoleRepo) GetRole(id uuid.UUID) (*Role, error)        { return nil, nil }
+func (r *fakeRoleRepo) ListRoles() ([]Role, error)                 { return nil, nil }
+func (r *fakeRoleRepo) UpdateRole(id uuid.UUID, name string) error { return nil }
+func (r *fakeRoleRepo) DeleteRole(id uuid.UUID) error              { return nil }
+func (r *fakeRoleRepo) AssignPermissions(roleID uuid.UUID, names []string) error {
+	return nil
+}
+
+func (r *fakeRoleRepo) PermissionsForUser(userID uuid.UUID) ([]string, error) {
+	r.permCalls++
+	return r.permissionsForUser[userID], nil
+}
+
+func (r *fakeRoleRepo) AssignUserRole(userID, roleID uuid.UUID) error {
+	r.permissionsForUser[userID] = append(r.permissionsForUser[userID], roleID.String())
+	return nil
+}
+
+func (r *fakeRoleRepo) RemoveUserRole(userID, roleID uuid.UUID) error {
+	return nil
+}
+
+type fakeTokenRevoker struct {
+	revoked []uuid.UUID
+}
+
+func (r *fakeTokenRevoker) MarkUserRevoked(userID uuid.UUID) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+func TestPermissionsForUserCaches(t *testing.T) {
+	repo := newFakeRoleRepo()
+	userID := uuid.New()
+	repo.permissionsForUser[userID] = []string{"users:read"}
+
+	svc, err := NewRoleService(repo, &fakeTokenRevoker{}, 16)
+	if err != nil {
+		t.Fatalf("NewRoleService returned error: %v", err)
+	}
+
+	if _, err := svc.PermissionsForUser(userID); err != nil {
+		t.Fatalf("PermissionsForUser returned error: %v", err)
+	}
+
+	if _, err := svc.PermissionsForUser(userID); err != nil {
+		t.Fatalf("PermissionsForUser returned error: %v", err)
+	}
+
+	if repo.permCalls != 1 {
+		t.Errorf("repo.PermissionsForUser called %d times, want 1 (second call should be served from cache)", repo.permCalls)
+	}
+}
+
+func TestInvalidateUserBustsCache(t *testing.T) {
+	repo := newFakeRoleRepo()
+	userID := uuid.New()
+	repo.permissionsForUser[userID] = []string{"users:read"}
+
+	svc, err := NewRoleService(repo, &fakeTokenRevoker{}, 16)
+	if err != nil {
+		t.Fatalf("NewRoleService returned error: %v", err)
+	}
+
+	if _, err := svc.PermissionsForUser(userID); err != nil {
+		t.Fatalf("PermissionsForUser returned error: %v", err)
+	}
+
+	svc.InvalidateUser(userID)
+
+	if _, err := svc.PermissionsForUser(userID); err != nil {
+		t.Fatalf("PermissionsForUser returned error: %v", err)
+	}
+
+	if repo.permCalls != 2 {
+		t.Errorf("repo.PermissionsForUser called %d times, want 2 (cache should be busted after InvalidateUser)", repo.permCalls)
+	}
+}
+
+func TestAssignUserRoleInvalidatesCache(t *testing.T) {
+	repo := newFakeRoleRepo()
+	revoker := &fakeTokenRevoker{}
+	userID := uuid.New()
+	roleID := uuid.New()
+
+	svc, err := NewRoleService(repo, revoker, 16)
+	if err != nil {
+		t.Fatalf("NewRoleService returned error: %v", err)
+	}
+
+	if perms, err := svc.PermissionsForUser(userID); err != nil || len(perms) != 0 {
+		t.Fatalf("PermissionsForUser = (%v, %v), want (empty, nil)", perms, err)
+	}
+
+	if err := svc.AssignUserRole(userID, roleID); err != nil {
+		t.Fatalf("AssignUserRole returned error: %v", err)
+	}
+
+	perms, err := svc.PermissionsForUser(userID)
+	if err != nil {
+		t.Fatalf("PermissionsForUser returned error: %v", err)
+	}
+
+	if len(perms) != 1 || perms[0] != roleID.String() {
+		t.Errorf("PermissionsForUser = %v, want [%q] (stale cache not invalidated by AssignUserRole)", perms, roleID.String())
+	}
+
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != userID {
+		t.Errorf("revoker.revoked = %v, want [%v] (AssignUserRole must revoke the user's existing JWT)", revoker.revoked, userID)
+	}
+}
+
+func TestRemoveUserRoleRevokesExistingSessions(t *testing.T) {
+	repo := newFakeRoleRepo()
+	revoker := &fakeTokenRevoker{}
+	userID := uuid.New()
+	roleID := uuid.New()
+
+	svc, err := NewRoleService(repo, revoker, 16)
+	if err != nil {
+		t.Fatalf("NewRoleService returned error: %v", err)
+	}
+
+	if err := svc.RemoveUserRole(userID, roleID); err != nil {
+		t.Fatalf("RemoveUserRole returned error: %v", err)
+	}
+
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != userID {
+		t.Errorf("revoker.revoked = %v, want [%v] (RemoveUserRole must revoke the user's existing JWT)", revoker.revoked, userID)
+	}
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"net/http"
+	"todo-app/pkg/clients"
+)
+
+var (
+	ErrEmailExisted           = clients.NewAppError(http.StatusBadRequest, nil, "email already existed", "ERR_EMAIL_EXISTED")
+	ErrEmailOrPasswordInvalid = clients.NewAppError(http.StatusBadRequest, nil, "email or password invalid", "ERR_EMAIL_OR_PASSWORD_INVALID")
+	ErrRefreshTokenInvalid    = clients.NewAppError(http.StatusUnauthorized, nil, "refresh token invalid or expired", "ERR_REFRESH_TOKEN_INVALID")
+	ErrEmailNotVerified       = clients.NewAppError(http.StatusForbidden, nil, "email address is not verified", "ERR_EMAIL_NOT_VERIFIED")
+	ErrTokenInvalid           = clients.NewAppError(http.StatusBadRequest, nil, "token invalid, expired or already used", "ERR_TOKEN_INVALID")
+	ErrTOTPCodeInvalid        = clients.NewAppError(http.StatusBadRequest, nil, "totp code or recovery code invalid", "ERR_TOTP_CODE_INVALID")
+	ErrMFAChallengeInvalid    = clients.NewAppError(http.StatusUnauthorized, nil, "mfa challenge invalid or expired", "ERR_MFA_CHALLENGE_INVALID")
+	ErrOAuthEmailNotVerified  = clients.NewAppError(http.StatusForbidden, nil, "oauth provider did not return a verified email", "ERR_OAUTH_EMAIL_NOT_VERIFIED")
+)
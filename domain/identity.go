@@ -0,0 +1,15 @@
+package domain
+
+import "github.com/google/uuid"
+
+// UserIdentity links a local user account to an identity on an external
+// OAuth2/OIDC provider, keyed by the provider's own stable subject ID rather
+// than email (emails can change or be reused across providers).
+type UserIdentity struct {
+	ID       uuid.UUID `json:"id" gorm:"column:id"`
+	UserID   uuid.UUID `json:"user_id" gorm:"column:user_id"`
+	Provider string    `json:"provider" gorm:"column:provider"`
+	Subject  string    `json:"subject" gorm:"column:subject"`
+}
+
+func (UserIdentity) TableName() string { return "user_identities" }
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is the legacy single-role marker kept for backward compatibility;
+// RBAC authorization now goes through the authz/RoleService permission
+// grants instead of this field.
+type Role int
+
+const (
+	RoleUser Role = iota + 1
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "user"
+	}
+}
+
+type User struct {
+	ID            uuid.UUID  `json:"id" gorm:"column:id"`
+	Email         string     `json:"email" gorm:"column:email"`
+	Password      string     `json:"-" gorm:"column:password"`
+	Salt          string     `json:"-" gorm:"column:salt"`
+	Role          Role       `json:"role" gorm:"column:role"`
+	EmailVerified bool       `json:"email_verified" gorm:"column:email_verified"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty" gorm:"column:verified_at"`
+	TOTPSecret    string     `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabled   bool       `json:"totp_enabled" gorm:"column:totp_enabled"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (User) TableName() string { return "users" }
+
+type UserCreate struct {
+	ID            uuid.UUID `json:"id" gorm:"column:id"`
+	Email         string    `json:"email" gorm:"column:email"`
+	Password      string    `json:"password" gorm:"column:password"`
+	Salt          string    `json:"-" gorm:"column:salt"`
+	Role          Role      `json:"-" gorm:"column:role"`
+	EmailVerified bool      `json:"-" gorm:"column:email_verified"`
+}
+
+func (UserCreate) TableName() string { return User{}.TableName() }
+
+func (data *UserCreate) Validate() error {
+	if data.Email == "" || data.Password == "" {
+		return ErrEmailOrPasswordInvalid
+	}
+
+	return nil
+}
+
+type UserLogin struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type UserUpdate struct {
+	Email         string     `json:"email"`
+	Password      string     `json:"-" gorm:"column:password"`
+	Salt          string     `json:"-" gorm:"column:salt"`
+	EmailVerified bool       `json:"-" gorm:"column:email_verified"`
+	VerifiedAt    *time.Time `json:"-" gorm:"column:verified_at"`
+	TOTPSecret    string     `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabled   bool       `json:"-" gorm:"column:totp_enabled"`
+}
+
+func (UserUpdate) TableName() string { return User{}.TableName() }
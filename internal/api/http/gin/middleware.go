@@ -0,0 +1,67 @@
+package gin
+
+import (
+	"net/http"
+	"time"
+	"todo-app/domain"
+	"todo-app/pkg/clients"
+	"todo-app/pkg/tokenprovider"
+	"todo-app/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// nearExpiryWindow is how close to its exp claim an access JWT has to be
+// before middlewareAuth pays the extra store lookup to check for
+// revocation. Most requests land well outside this window and skip it.
+const nearExpiryWindow = 1 * time.Minute
+
+// NewAuthMiddleware builds the middlewareAuth handler every protected route
+// is wired through: it validates the access JWT and stores the resulting
+// clients.Requester in the gin context. When the token is within
+// nearExpiryWindow of expiring, it also consults tokenRepo for a
+// logout/password-reset/role-change that happened after the token was
+// issued, and rejects the token if one did. Before that window a revoked
+// token is still accepted; the check only cuts off its last
+// nearExpiryWindow of life.
+func NewAuthMiddleware(tokenProvider tokenprovider.Provider, tokenRepo user.TokenRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawToken := c.GetHeader("Authorization")
+		if rawToken == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrRefreshTokenInvalid)
+
+			return
+		}
+
+		payload, err := tokenProvider.Validate(rawToken)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, clients.ErrInvalidRequest(err))
+
+			return
+		}
+
+		if payload.URole == user.MFAChallengeRole {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrMFAChallengeInvalid)
+
+			return
+		}
+
+		if time.Until(payload.ExpiresAt) <= nearExpiryWindow {
+			revokedAt, err := tokenRepo.UserRevokedAt(payload.UID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, clients.ErrInternal(err))
+
+				return
+			}
+
+			if revokedAt.After(payload.IssuedAt) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrRefreshTokenInvalid)
+
+				return
+			}
+		}
+
+		c.Set(clients.CurrentUser, payload)
+		c.Next()
+	}
+}
@@ -0,0 +1,265 @@
+package gin
+
+import (
+	"net/http"
+	"todo-app/authz"
+	"todo-app/pkg/clients"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoleService interface {
+	CreateRole(name string) (*authz.Role, error)
+	GetRole(id uuid.UUID) (*authz.Role, error)
+	ListRoles() ([]authz.Role, error)
+	UpdateRole(id uuid.UUID, name string) error
+	DeleteRole(id uuid.UUID) error
+	AssignPermissions(roleID uuid.UUID, permissionNames []string) error
+	AssignUserRole(userID, roleID uuid.UUID) error
+	RemoveUserRole(userID, roleID uuid.UUID) error
+}
+
+type roleHandler struct {
+	roleService RoleService
+}
+
+// NewRoleHandler registers the RBAC admin endpoints. Every route requires
+// the roles:write permission except the read-only routes (list and get by
+// ID), which only require roles:read.
+func NewRoleHandler(apiVersion *gin.RouterGroup, svc RoleService, middlewareAuth func(c *gin.Context)) {
+	roleHandler := &roleHandler{
+		roleService: svc,
+	}
+
+	roles := apiVersion.Group("/roles", middlewareAuth)
+	roles.POST("", authz.Require("roles:write"), roleHandler.CreateRoleHandler)
+	roles.GET("", authz.Require("roles:read"), roleHandler.ListRolesHandler)
+	roles.GET("/:id", authz.Require("roles:read"), roleHandler.GetRoleHandler)
+	roles.PATCH("/:id", authz.Require("roles:write"), roleHandler.UpdateRoleHandler)
+	roles.DELETE("/:id", authz.Require("roles:write"), roleHandler.DeleteRoleHandler)
+	roles.POST("/:id/permissions", authz.Require("roles:write"), roleHandler.AssignPermissionsHandler)
+	roles.POST("/:id/users/:user_id", authz.Require("roles:write"), roleHandler.AssignUserRoleHandler)
+	roles.DELETE("/:id/users/:user_id", authz.Require("roles:write"), roleHandler.RemoveUserRoleHandler)
+}
+
+// CreateRoleRequest is the body accepted by CreateRoleHandler.
+type CreateRoleRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateRoleHandler godoc
+// @Summary Create a role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param data body CreateRoleRequest true "Role name"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles [post]
+func (h *roleHandler) CreateRoleHandler(c *gin.Context) {
+	var data CreateRoleRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	role, err := h.roleService.CreateRole(data.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(role))
+}
+
+// ListRolesHandler godoc
+// @Summary List roles
+// @Tags roles
+// @Produce json
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles [get]
+func (h *roleHandler) ListRolesHandler(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(roles))
+}
+
+// GetRoleHandler godoc
+// @Summary Get a role by ID
+// @Tags roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles/{id} [get]
+func (h *roleHandler) GetRoleHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	role, err := h.roleService.GetRole(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(role))
+}
+
+// UpdateRoleHandler godoc
+// @Summary Rename a role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param data body CreateRoleRequest true "New role name"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles/{id} [patch]
+func (h *roleHandler) UpdateRoleHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	var data CreateRoleRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.roleService.UpdateRole(id, data.Name); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// DeleteRoleHandler godoc
+// @Summary Delete a role
+// @Tags roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles/{id} [delete]
+func (h *roleHandler) DeleteRoleHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.roleService.DeleteRole(id); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// AssignPermissionsRequest is the body accepted by AssignPermissionsHandler.
+type AssignPermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// AssignPermissionsHandler godoc
+// @Summary Replace a role's permissions
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param data body AssignPermissionsRequest true "Permission names"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles/{id}/permissions [post]
+func (h *roleHandler) AssignPermissionsHandler(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	var data AssignPermissionsRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.roleService.AssignPermissions(id, data.Permissions); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// AssignUserRoleHandler godoc
+// @Summary Grant a role to a user
+// @Tags roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles/{id}/users/{user_id} [post]
+func (h *roleHandler) AssignUserRoleHandler(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.roleService.AssignUserRole(userID, roleID); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// RemoveUserRoleHandler godoc
+// @Summary Revoke a role from a user
+// @Tags roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /roles/{id}/users/{user_id} [delete]
+func (h *roleHandler) RemoveUserRoleHandler(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.roleService.RemoveUserRole(userID, roleID); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
@@ -3,9 +3,12 @@ package gin
 import (
 	"fmt"
 	"net/http"
+	"todo-app/authz"
 	"todo-app/domain"
 	"todo-app/pkg/clients"
 	"todo-app/pkg/tokenprovider"
+	"todo-app/pkg/util"
+	"todo-app/user"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,8 +16,23 @@ import (
 
 type UserService interface {
 	Register(data *domain.UserCreate) error
-	Login(data *domain.UserLogin) (tokenprovider.Token, error)
-	GetAllUser() ([]domain.User, error)
+	Login(data *domain.UserLogin) (user.LoginResult, error)
+	LoginTOTP(challengeToken, code string) (tokenprovider.TokenPair, error)
+	EnrollTOTP(userID uuid.UUID) (secret string, otpauthURL string, qrPNG []byte, err error)
+	VerifyTOTP(userID uuid.UUID, code string) (recoveryCodes []string, err error)
+	DisableTOTP(userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(userID uuid.UUID, code string) (recoveryCodes []string, err error)
+	RefreshToken(rawRefresh string) (tokenprovider.TokenPair, error)
+	Logout(rawRefresh string) error
+	OAuthAuthCodeURL(providerName, state string) (string, error)
+	OAuthLogin(providerName, code string) (user.LoginResult, error)
+	LinkIdentity(userID uuid.UUID, providerName, code string) error
+	UnlinkIdentity(userID uuid.UUID, providerName string) error
+	RequestEmailVerification(email string) error
+	VerifyEmail(rawToken string) error
+	RequestPasswordReset(email string) error
+	ConfirmPasswordReset(rawToken, newPassword string) error
+	GetAllUser(filter user.UserFilter, paging *user.Paging) ([]domain.User, error)
 	GetUserByID(id uuid.UUID) (domain.User, error)
 	UpdateUser(id uuid.UUID, user *domain.UserUpdate) error
 	DeleteUser(id uuid.UUID) error
@@ -40,10 +58,25 @@ func NewUserHandler(apiVersion *gin.RouterGroup, svc UserService, middlewareAuth
 	users := apiVersion.Group("/users")
 	users.POST("/register", userHandler.RegisterUserHandler)
 	users.POST("/login", userHandler.LoginHandler)
+	users.POST("/login/2fa", userHandler.LoginTOTPHandler)
+	users.POST("/2fa/enroll", middlewareAuth, userHandler.EnrollTOTPHandler)
+	users.POST("/2fa/verify", middlewareAuth, userHandler.VerifyTOTPHandler)
+	users.POST("/2fa/disable", middlewareAuth, userHandler.DisableTOTPHandler)
+	users.POST("/2fa/recovery/regenerate", middlewareAuth, userHandler.RegenerateRecoveryCodesHandler)
+	users.POST("/refresh", userHandler.RefreshTokenHandler)
+	users.POST("/logout", userHandler.LogoutHandler)
+	users.GET("/oauth/:provider/login", userHandler.OAuthLoginHandler)
+	users.GET("/oauth/:provider/callback", userHandler.OAuthCallbackHandler)
+	users.POST("/link/:provider", middlewareAuth, userHandler.LinkIdentityHandler)
+	users.DELETE("/link/:provider", middlewareAuth, userHandler.UnlinkIdentityHandler)
+	users.POST("/verify/request", userHandler.RequestEmailVerificationHandler)
+	users.GET("/verify/:token", userHandler.VerifyEmailHandler)
+	users.POST("/password/reset/request", userHandler.RequestPasswordResetHandler)
+	users.POST("/password/reset/confirm", userHandler.ConfirmPasswordResetHandler)
 	users.GET("/", userHandler.GetAllUserHandler)
 	users.GET("/:id", middlewareAuth, userHandler.GetUserHandler)
 	users.PATCH("/:id", middlewareAuth, userHandler.UpdateUserHandler)
-	users.DELETE("/:id", userHandler.DeleteUserHandler)
+	users.DELETE("/:id", middlewareAuth, userHandler.DeleteUserHandler)
 }
 
 // RegisterUserHandler godoc
@@ -97,7 +130,270 @@ func (h *userHandler) LoginHandler(c *gin.Context) {
 		return
 	}
 
-	token, err := h.userService.Login(&data)
+	result, err := h.userService.Login(&data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(result))
+}
+
+// LoginTOTPRequest is the body accepted by LoginTOTPHandler.
+type LoginTOTPRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// LoginTOTPHandler godoc
+// @Summary Complete a login that required a second factor
+// @Description Accept the mfa_required challenge token plus a 6-digit TOTP code (or a one-use recovery code) and return the real access+refresh pair
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body LoginTOTPRequest true "Challenge token and code"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/login/2fa [post]
+func (h *userHandler) LoginTOTPHandler(c *gin.Context) {
+	var data LoginTOTPRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	token, err := h.userService.LoginTOTP(data.ChallengeToken, data.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(token))
+}
+
+// TOTPCodeRequest is the body shared by VerifyTOTPHandler, DisableTOTPHandler
+// and RegenerateRecoveryCodesHandler.
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6"`
+}
+
+// EnrollTOTPHandler godoc
+// @Summary Start TOTP enrollment
+// @Description Generate a TOTP secret for the current user and return it plus an otpauth URL and QR PNG
+// @Tags users
+// @Produce json
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/2fa/enroll [post]
+func (h *userHandler) EnrollTOTPHandler(c *gin.Context) {
+	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+	secret, otpauthURL, qrPNG, err := h.userService.EnrollTOTP(requester.GetUserID())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(gin.H{
+		"secret":      secret,
+		"otpauth_url": otpauthURL,
+		"qr_png":      qrPNG,
+	}))
+}
+
+// VerifyTOTPHandler godoc
+// @Summary Confirm TOTP enrollment
+// @Description Confirm a code generated from the enrolled secret, flip TOTPEnabled on, and return recovery codes
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body TOTPCodeRequest true "Current TOTP code"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/2fa/verify [post]
+func (h *userHandler) VerifyTOTPHandler(c *gin.Context) {
+	var data TOTPCodeRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+	recoveryCodes, err := h.userService.VerifyTOTP(requester.GetUserID(), data.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(gin.H{"recovery_codes": recoveryCodes}))
+}
+
+// DisableTOTPHandler godoc
+// @Summary Disable TOTP
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body TOTPCodeRequest true "Current TOTP code"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/2fa/disable [post]
+func (h *userHandler) DisableTOTPHandler(c *gin.Context) {
+	var data TOTPCodeRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+	if err := h.userService.DisableTOTP(requester.GetUserID(), data.Code); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// RegenerateRecoveryCodesHandler godoc
+// @Summary Regenerate TOTP recovery codes
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body TOTPCodeRequest true "Current TOTP code"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/2fa/recovery/regenerate [post]
+func (h *userHandler) RegenerateRecoveryCodesHandler(c *gin.Context) {
+	var data TOTPCodeRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+	recoveryCodes, err := h.userService.RegenerateRecoveryCodes(requester.GetUserID(), data.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(gin.H{"recovery_codes": recoveryCodes}))
+}
+
+// RefreshTokenRequest is the body accepted by RefreshTokenHandler and
+// LogoutHandler.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshTokenHandler godoc
+// @Summary Rotate a refresh token for a new access token
+// @Description Validate a refresh token against the session store, rotate it and return a fresh access+refresh pair
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Failure      401  {object}  clients.AppError
+// @Router /users/refresh [post]
+func (h *userHandler) RefreshTokenHandler(c *gin.Context) {
+	var data RefreshTokenRequest
+
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+
+		return
+	}
+
+	token, err := h.userService.RefreshToken(data.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(token))
+}
+
+// LogoutHandler godoc
+// @Summary Log out a session
+// @Description Revoke a refresh token so it can no longer mint new access tokens
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/logout [post]
+func (h *userHandler) LogoutHandler(c *gin.Context) {
+	var data RefreshTokenRequest
+
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+
+		return
+	}
+
+	if err := h.userService.Logout(data.RefreshToken); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// OAuthLoginHandler godoc
+// @Summary Start a social login
+// @Description Redirect the browser to the provider's OAuth2/OIDC consent screen
+// @Tags users
+// @Param provider path string true "Provider name (google, github)"
+// @Success 307
+// @Failure      400  {object}  clients.AppError
+// @Router /users/oauth/{provider}/login [get]
+func (h *userHandler) OAuthLoginHandler(c *gin.Context) {
+	provider := c.Param("provider")
+	state := util.GenSalt(32)
+
+	url, err := h.userService.OAuthAuthCodeURL(provider, state)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err)
+
+		return
+	}
+
+	c.SetCookie("oauth_state", state, int(10*60), "/", "", false, true)
+	c.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// OAuthCallbackHandler godoc
+// @Summary Complete a social login
+// @Description Exchange the provider's authorization code and return the login result: an access+refresh pair, or an mfa_required challenge when the user has TOTP enabled
+// @Tags users
+// @Param provider path string true "Provider name (google, github)"
+// @Param code query string true "Authorization code"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/oauth/{provider}/callback [get]
+func (h *userHandler) OAuthCallbackHandler(c *gin.Context) {
+	provider := c.Param("provider")
+	code := c.Query("code")
+	state := c.Query("state")
+
+	cookieState, err := c.Cookie("oauth_state")
+	c.SetCookie("oauth_state", "", -1, "/", "", false, true)
+
+	if err != nil || state == "" || state != cookieState {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(fmt.Errorf("oauth state mismatch")))
+
+		return
+	}
+
+	token, err := h.userService.OAuthLogin(provider, code)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, err)
 
@@ -107,25 +403,205 @@ func (h *userHandler) LoginHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(token))
 }
 
+// LinkIdentityRequest is the body accepted by LinkIdentityHandler.
+type LinkIdentityRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// LinkIdentityHandler godoc
+// @Summary Link a social identity to the current user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name (google, github)"
+// @Param data body LinkIdentityRequest true "Authorization code"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/link/{provider} [post]
+func (h *userHandler) LinkIdentityHandler(c *gin.Context) {
+	provider := c.Param("provider")
+
+	var data LinkIdentityRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+
+		return
+	}
+
+	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+	if err := h.userService.LinkIdentity(requester.GetUserID(), provider, data.Code); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// UnlinkIdentityHandler godoc
+// @Summary Unlink a social identity from the current user
+// @Tags users
+// @Produce json
+// @Param provider path string true "Provider name (google, github)"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/link/{provider} [delete]
+func (h *userHandler) UnlinkIdentityHandler(c *gin.Context) {
+	provider := c.Param("provider")
+	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+	if err := h.userService.UnlinkIdentity(requester.GetUserID(), provider); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// EmailRequest is the body accepted by handlers that only need an email
+// address: RequestEmailVerificationHandler and RequestPasswordResetHandler.
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// RequestEmailVerificationHandler godoc
+// @Summary Request an account verification email
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body EmailRequest true "Account email"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/verify/request [post]
+func (h *userHandler) RequestEmailVerificationHandler(c *gin.Context) {
+	var data EmailRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.userService.RequestEmailVerification(data.Email); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// VerifyEmailHandler godoc
+// @Summary Confirm account verification
+// @Tags users
+// @Produce json
+// @Param token path string true "Verification token"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/verify/{token} [get]
+func (h *userHandler) VerifyEmailHandler(c *gin.Context) {
+	token := c.Param("token")
+
+	if err := h.userService.VerifyEmail(token); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// RequestPasswordResetHandler godoc
+// @Summary Request a password reset email
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body EmailRequest true "Account email"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/password/reset/request [post]
+func (h *userHandler) RequestPasswordResetHandler(c *gin.Context) {
+	var data EmailRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.userService.RequestPasswordReset(data.Email); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
+// ConfirmPasswordResetRequest is the body accepted by ConfirmPasswordResetHandler.
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// ConfirmPasswordResetHandler godoc
+// @Summary Confirm a password reset
+// @Description Consume a reset token, set a new password and invalidate all existing sessions
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param data body ConfirmPasswordResetRequest true "Reset token and new password"
+// @Success 200 {object} clients.SuccessRes
+// @Failure      400  {object}  clients.AppError
+// @Router /users/password/reset/confirm [post]
+func (h *userHandler) ConfirmPasswordResetHandler(c *gin.Context) {
+	var data ConfirmPasswordResetRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := h.userService.ConfirmPasswordReset(data.Token, data.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(true))
+}
+
 // GetAllUserHandler godoc
 // @Summary Get all users
-// @Description Retrieve a list of all users
+// @Description Retrieve a paged list of users, with optional filtering and offset or keyset (cursor) pagination
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param email query string false "Filter by email (LIKE)"
+// @Param role query int false "Filter by role"
+// @Param q query string false "Free-text search"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Page size"
+// @Param mode query string false "Pagination mode: offset (default) or cursor"
+// @Param cursor query string false "Opaque cursor returned by a previous cursor-mode call"
 // @Success 200 {object} clients.SuccessRes
 // @Failure      400  {object}  clients.AppError
 // @Failure      404  {object}  clients.AppError
 // @Failure      500  {object}  clients.AppError
 // @Router /users [get]
 func (h *userHandler) GetAllUserHandler(c *gin.Context) {
-	users, err := h.userService.GetAllUser()
+	var filter user.UserFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	var paging user.Paging
+	if err := c.ShouldBindQuery(&paging); err != nil {
+		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
+		return
+	}
+
+	users, err := h.userService.GetAllUser(filter, &paging)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, clients.ErrInvalidRequest(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, clients.SimpleSuccessResponse(users))
+	c.JSON(http.StatusOK, &clients.SuccessRes{Data: users, Paging: paging})
 }
 
 // GetUserHandler godoc
@@ -179,11 +655,9 @@ func (h *userHandler) UpdateUserHandler(c *gin.Context) {
 
 		return
 	}
-	var user1 domain.User
 	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
-	user1.ID = requester.GetUserID()
 
-	if user1.ID != id {
+	if requester.GetUserID() != id && !authz.HasPermission(requester, "users:write") {
 		c.JSON(http.StatusUnauthorized, clients.ErrInvalidRequest(fmt.Errorf("unauthorized: ID does not match")))
 		return
 	}
@@ -217,6 +691,14 @@ func (h *userHandler) DeleteUserHandler(c *gin.Context) {
 		return
 	}
 
+	requester := c.MustGet(clients.CurrentUser).(clients.Requester)
+
+	if requester.GetUserID() != id && !authz.HasPermission(requester, "users:write") {
+		c.JSON(http.StatusUnauthorized, clients.ErrInvalidRequest(fmt.Errorf("unauthorized: ID does not match")))
+
+		return
+	}
+
 	if err := h.userService.DeleteUser(id); err != nil {
 		c.JSON(http.StatusBadRequest, err)
 
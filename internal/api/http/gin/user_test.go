@@ -0,0 +1,159 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app/domain"
+	"todo-app/pkg/tokenprovider"
+	"todo-app/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeUserService stubs UserService, returning zero values for every method
+// except the ones a given test configures - OAuthCallbackHandler only ever
+// needs OAuthLogin.
+type fakeUserService struct {
+	oauthLoginCalls int
+	oauthLoginErr   error
+}
+
+func (s *fakeUserService) Register(*domain.UserCreate) error { return nil }
+
+func (s *fakeUserService) Login(*domain.UserLogin) (user.LoginResult, error) {
+	return user.LoginResult{}, nil
+}
+
+func (s *fakeUserService) LoginTOTP(string, string) (tokenprovider.TokenPair, error) {
+	return tokenprovider.TokenPair{}, nil
+}
+
+func (s *fakeUserService) EnrollTOTP(uuid.UUID) (string, string, []byte, error) {
+	return "", "", nil, nil
+}
+
+func (s *fakeUserService) VerifyTOTP(uuid.UUID, string) ([]string, error) { return nil, nil }
+
+func (s *fakeUserService) DisableTOTP(uuid.UUID, string) error { return nil }
+
+func (s *fakeUserService) RegenerateRecoveryCodes(uuid.UUID, string) ([]string, error) {
+	return nil, nil
+}
+
+func (s *fakeUserService) RefreshToken(string) (tokenprovider.TokenPair, error) {
+	return tokenprovider.TokenPair{}, nil
+}
+
+func (s *fakeUserService) Logout(string) error { return nil }
+
+func (s *fakeUserService) OAuthAuthCodeURL(string, string) (string, error) { return "", nil }
+
+func (s *fakeUserService) OAuthLogin(providerName, code string) (user.LoginResult, error) {
+	s.oauthLoginCalls++
+	return user.LoginResult{}, s.oauthLoginErr
+}
+
+func (s *fakeUserService) LinkIdentity(uuid.UUID, string, string) error { return nil }
+
+func (s *fakeUserService) UnlinkIdentity(uuid.UUID, string) error { return nil }
+
+func (s *fakeUserService) RequestEmailVerification(string) error { return nil }
+
+func (s *fakeUserService) VerifyEmail(string) error { return nil }
+
+func (s *fakeUserService) RequestPasswordReset(string) error { return nil }
+
+func (s *fakeUserService) ConfirmPasswordReset(string, string) error { return nil }
+
+func (s *fakeUserService) GetAllUser(user.UserFilter, *user.Paging) ([]domain.User, error) {
+	return nil, nil
+}
+
+func (s *fakeUserService) GetUserByID(uuid.UUID) (domain.User, error) { return domain.User{}, nil }
+
+func (s *fakeUserService) UpdateUser(uuid.UUID, *domain.UserUpdate) error { return nil }
+
+func (s *fakeUserService) DeleteUser(uuid.UUID) error { return nil }
+
+func newCallbackTestContext(query string, cookieState string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/oauth/google/callback?"+query, nil)
+	if cookieState != "" {
+		req.AddCookie(&http.Cookie{Name: "oauth_state", Value: cookieState})
+	}
+
+	c.Request = req
+	c.Params = gin.Params{{Key: "provider", Value: "google"}}
+
+	return c, rec
+}
+
+func TestOAuthCallbackHandlerRejectsMissingCookie(t *testing.T) {
+	svc := &fakeUserService{}
+	h := &userHandler{userService: svc}
+
+	c, rec := newCallbackTestContext("code=abc&state=xyz", "")
+	h.OAuthCallbackHandler(c)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	if svc.oauthLoginCalls != 0 {
+		t.Error("OAuthLogin was called despite a missing oauth_state cookie")
+	}
+}
+
+func TestOAuthCallbackHandlerRejectsStateMismatch(t *testing.T) {
+	svc := &fakeUserService{}
+	h := &userHandler{userService: svc}
+
+	c, rec := newCallbackTestContext("code=abc&state=xyz", "not-xyz")
+	h.OAuthCallbackHandler(c)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	if svc.oauthLoginCalls != 0 {
+		t.Error("OAuthLogin was called despite a state/cookie mismatch")
+	}
+}
+
+func TestOAuthCallbackHandlerRejectsEmptyState(t *testing.T) {
+	svc := &fakeUserService{}
+	h := &userHandler{userService: svc}
+
+	c, rec := newCallbackTestContext("code=abc&state=", "xyz")
+	h.OAuthCallbackHandler(c)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	if svc.oauthLoginCalls != 0 {
+		t.Error("OAuthLogin was called despite an empty state query param")
+	}
+}
+
+func TestOAuthCallbackHandlerAcceptsMatchingState(t *testing.T) {
+	svc := &fakeUserService{}
+	h := &userHandler{userService: svc}
+
+	c, rec := newCallbackTestContext("code=abc&state=xyz", "xyz")
+	h.OAuthCallbackHandler(c)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if svc.oauthLoginCalls != 1 {
+		t.Errorf("OAuthLogin called %d times, want 1 once state validation passes", svc.oauthLoginCalls)
+	}
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"errors"
+	"todo-app/domain"
+	"todo-app/pkg/clients"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type identityRepo struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepo(db *gorm.DB) *identityRepo {
+	return &identityRepo{
+		db: db,
+	}
+}
+
+func (r *identityRepo) GetIdentity(provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, clients.ErrRecordNotFound
+		}
+
+		return nil, clients.ErrDB(err)
+	}
+
+	return &identity, nil
+}
+
+func (r *identityRepo) SaveIdentity(identity *domain.UserIdentity) error {
+	if err := r.db.Create(identity).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *identityRepo) DeleteIdentity(userID uuid.UUID, provider string) error {
+	if err := r.db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&domain.UserIdentity{}).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"todo-app/pkg/clients"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type recoveryCodeModel struct {
+	UserID     string `gorm:"column:user_id"`
+	CodeHash   string `gorm:"column:code_hash;primaryKey"`
+	ConsumedAt *int64 `gorm:"column:consumed_at"`
+}
+
+func (recoveryCodeModel) TableName() string { return "user_recovery_codes" }
+
+type recoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepo(db *gorm.DB) *recoveryCodeRepo {
+	return &recoveryCodeRepo{
+		db: db,
+	}
+}
+
+func (r *recoveryCodeRepo) SaveRecoveryCodes(userID uuid.UUID, hashedCodes []string) error {
+	models := make([]recoveryCodeModel, len(hashedCodes))
+	for i, hash := range hashedCodes {
+		models[i] = recoveryCodeModel{UserID: userID.String(), CodeHash: hash}
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID.String()).Delete(&recoveryCodeModel{}).Error; err != nil {
+			return clients.ErrDB(err)
+		}
+
+		if err := tx.Create(&models).Error; err != nil {
+			return clients.ErrDB(err)
+		}
+
+		return nil
+	})
+}
+
+func (r *recoveryCodeRepo) ConsumeRecoveryCode(userID uuid.UUID, hashedCode string) error {
+	result := r.db.Where("user_id = ? AND code_hash = ? AND consumed_at IS NULL", userID.String(), hashedCode).
+		Delete(&recoveryCodeModel{})
+	if result.Error != nil {
+		return clients.ErrDB(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return clients.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *recoveryCodeRepo) DeleteRecoveryCodes(userID uuid.UUID) error {
+	if err := r.db.Where("user_id = ?", userID.String()).Delete(&recoveryCodeModel{}).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"errors"
+	"todo-app/authz"
+	"todo-app/pkg/clients"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type roleModel struct {
+	ID   string `gorm:"column:id;primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (roleModel) TableName() string { return "roles" }
+
+type permissionModel struct {
+	ID   string `gorm:"column:id;primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (permissionModel) TableName() string { return "permissions" }
+
+type rolePermissionModel struct {
+	RoleID       string `gorm:"column:role_id"`
+	PermissionID string `gorm:"column:permission_id"`
+}
+
+func (rolePermissionModel) TableName() string { return "role_permissions" }
+
+type userRoleModel struct {
+	UserID string `gorm:"column:user_id"`
+	RoleID string `gorm:"column:role_id"`
+}
+
+func (userRoleModel) TableName() string { return "user_roles" }
+
+type roleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepo(db *gorm.DB) *roleRepo {
+	return &roleRepo{
+		db: db,
+	}
+}
+
+func (r *roleRepo) CreateRole(role *authz.Role) error {
+	model := roleModel{ID: role.ID.String(), Name: role.Name}
+
+	if err := r.db.Create(&model).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *roleRepo) GetRole(id uuid.UUID) (*authz.Role, error) {
+	var model roleModel
+
+	if err := r.db.Where("id = ?", id.String()).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, clients.ErrRecordNotFound
+		}
+
+		return nil, clients.ErrDB(err)
+	}
+
+	return roleFromModel(model)
+}
+
+func (r *roleRepo) ListRoles() ([]authz.Role, error) {
+	var models []roleModel
+
+	if err := r.db.Find(&models).Error; err != nil {
+		return nil, clients.ErrDB(err)
+	}
+
+	roles := make([]authz.Role, 0, len(models))
+	for _, m := range models {
+		role, err := roleFromModel(m)
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, *role)
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepo) UpdateRole(id uuid.UUID, name string) error {
+	if err := r.db.Model(&roleModel{}).Where("id = ?", id.String()).Update("name", name).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *roleRepo) DeleteRole(id uuid.UUID) error {
+	if err := r.db.Where("id = ?", id.String()).Delete(&roleModel{}).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *roleRepo) AssignPermissions(roleID uuid.UUID, permissionNames []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", roleID.String()).Delete(&rolePermissionModel{}).Error; err != nil {
+			return clients.ErrDB(err)
+		}
+
+		var permissions []permissionModel
+		if err := tx.Where("name IN ?", permissionNames).Find(&permissions).Error; err != nil {
+			return clients.ErrDB(err)
+		}
+
+		links := make([]rolePermissionModel, len(permissions))
+		for i, p := range permissions {
+			links[i] = rolePermissionModel{RoleID: roleID.String(), PermissionID: p.ID}
+		}
+
+		if len(links) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&links).Error; err != nil {
+			return clients.ErrDB(err)
+		}
+
+		return nil
+	})
+}
+
+func (r *roleRepo) PermissionsForUser(userID uuid.UUID) ([]string, error) {
+	var names []string
+
+	err := r.db.Table(permissionModel{}.TableName()+" AS p").
+		Joins("JOIN "+rolePermissionModel{}.TableName()+" AS rp ON rp.permission_id = p.id").
+		Joins("JOIN "+userRoleModel{}.TableName()+" AS ur ON ur.role_id = rp.role_id").
+		Where("ur.user_id = ?", userID.String()).
+		Distinct().
+		Pluck("p.name", &names).Error
+	if err != nil {
+		return nil, clients.ErrDB(err)
+	}
+
+	return names, nil
+}
+
+func (r *roleRepo) AssignUserRole(userID, roleID uuid.UUID) error {
+	link := userRoleModel{UserID: userID.String(), RoleID: roleID.String()}
+
+	if err := r.db.Where(link).FirstOrCreate(&link).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *roleRepo) RemoveUserRole(userID, roleID uuid.UUID) error {
+	link := userRoleModel{UserID: userID.String(), RoleID: roleID.String()}
+
+	if err := r.db.Where(link).Delete(&userRoleModel{}).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func roleFromModel(m roleModel) (*authz.Role, error) {
+	id, err := uuid.Parse(m.ID)
+	if err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	return &authz.Role{ID: id, Name: m.Name}, nil
+}
@@ -1,9 +1,14 @@
 package postgres
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 	"todo-app/domain"
 	"todo-app/pkg/clients"
+	"todo-app/user"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -41,20 +46,92 @@ func (r *userRepo) GetUser(conditions map[string]any) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *userRepo) GetAll() ([]domain.User, error) {
+func applyUserFilter(query *gorm.DB, filter user.UserFilter) *gorm.DB {
+	if filter.Email != "" {
+		query = query.Where("email LIKE ?", "%"+filter.Email+"%")
+	}
+
+	if filter.Role != 0 {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	if filter.CreatedAtFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAtFrom)
+	}
+
+	if filter.CreatedAtTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedAtTo)
+	}
+
+	if filter.Q != "" {
+		query = query.Where("email LIKE ?", "%"+filter.Q+"%")
+	}
+
+	return query
+}
+
+func encodeUserCursor(u domain.User) string {
+	raw := fmt.Sprintf("%d,%s", u.CreatedAt.UnixNano(), u.ID)
+
+	return base64.URLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeUserCursor(cursor string) (createdAt time.Time, id string, err error) {
+	raw, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", clients.ErrInvalidRequest(err)
+	}
+
+	parts := strings.SplitN(string(raw), ",", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", clients.ErrInvalidRequest(errors.New("malformed cursor"))
+	}
+
+	var nanos int64
+	if _, err := fmt.Sscanf(parts[0], "%d", &nanos); err != nil {
+		return time.Time{}, "", clients.ErrInvalidRequest(err)
+	}
+
+	return time.Unix(0, nanos), parts[1], nil
+}
+
+func (r *userRepo) GetAll(filter user.UserFilter, paging *user.Paging) ([]domain.User, error) {
 	users := []domain.User{}
 
-	
-
-	// var query *gorm.DB
-	// if err := query.Table(domain.User{}.TableName()).Select("id").Count(&paging.Total).Error; err != nil{
-	// 	return nil, clients.ErrDB(err)
-	// }
-	// query = r.db.Limit(paging.Limit).Offset((paging.Page - 1) * paging.Limit)
-	// if err := query.Find(&users).Error; err != nil {
-	// 	return nil, clients.ErrDB(err)
-	// }
-	if err := r.db.Find(&users).Error; err != nil {
+	countQuery := applyUserFilter(r.db.Model(&domain.User{}), filter)
+	if err := countQuery.Count(&paging.Total).Error; err != nil {
+		return nil, clients.ErrDB(err)
+	}
+
+	if paging.IsCursorMode() {
+		query := applyUserFilter(r.db, filter).Order("created_at ASC, id ASC").Limit(paging.Limit)
+
+		if paging.Cursor != "" {
+			createdAt, id, err := decodeUserCursor(paging.Cursor)
+			if err != nil {
+				return nil, err
+			}
+
+			query = query.Where("(created_at, id) > (?, ?::uuid)", createdAt, id)
+		}
+
+		if err := query.Find(&users).Error; err != nil {
+			return nil, clients.ErrDB(err)
+		}
+
+		if len(users) == paging.Limit {
+			paging.NextCursor = encodeUserCursor(users[len(users)-1])
+		}
+
+		return users, nil
+	}
+
+	query := applyUserFilter(r.db, filter).
+		Order("created_at ASC, id ASC").
+		Limit(paging.Limit).
+		Offset((paging.Page - 1) * paging.Limit)
+
+	if err := query.Find(&users).Error; err != nil {
 		return nil, clients.ErrDB(err)
 	}
 
@@ -69,6 +146,14 @@ func (r *userRepo) Update(id uuid.UUID, user *domain.UserUpdate) error {
 	return nil
 }
 
+func (r *userRepo) UpdateFields(id uuid.UUID, fields map[string]any) error {
+	if err := r.db.Model(&domain.User{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
 func (r *userRepo) Delete(id uuid.UUID) error {
 	if err := r.db.Table(domain.User{}.TableName()).Where("id = ?", id).Delete(nil).Error; err != nil {
 		return clients.ErrDB(err)
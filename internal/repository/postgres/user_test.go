@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestEncodeDecodeUserCursor(t *testing.T) {
+	u := domain.User{
+		ID:        uuid.New(),
+		CreatedAt: time.Unix(0, 1700000000123456789),
+	}
+
+	cursor := encodeUserCursor(u)
+
+	createdAt, id, err := decodeUserCursor(cursor)
+	if err != nil {
+		t.Fatalf("decodeUserCursor returned error: %v", err)
+	}
+
+	if !createdAt.Equal(u.CreatedAt) {
+		t.Errorf("createdAt = %v, want %v", createdAt, u.CreatedAt)
+	}
+
+	if id != u.ID.String() {
+		t.Errorf("id = %q, want %q", id, u.ID.String())
+	}
+}
+
+func TestDecodeUserCursorMalformed(t *testing.T) {
+	if _, _, err := decodeUserCursor("not-base64-!!!"); err == nil {
+		t.Fatal("expected error for invalid base64 cursor, got nil")
+	}
+
+	if _, _, err := decodeUserCursor(""); err == nil {
+		t.Fatal("expected error for empty cursor, got nil")
+	}
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+	"todo-app/pkg/clients"
+	"todo-app/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type userTokenModel struct {
+	TokenHash  string     `gorm:"column:token_hash;primaryKey"`
+	UserID     string     `gorm:"column:user_id"`
+	Purpose    string     `gorm:"column:purpose"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at"`
+	ConsumedAt *time.Time `gorm:"column:consumed_at"`
+}
+
+func (userTokenModel) TableName() string { return "user_tokens" }
+
+type userTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepo(db *gorm.DB) *userTokenRepo {
+	return &userTokenRepo{
+		db: db,
+	}
+}
+
+func (r *userTokenRepo) SaveUserToken(tokenHash string, t *user.UserToken) error {
+	model := userTokenModel{
+		TokenHash: tokenHash,
+		UserID:    t.UserID.String(),
+		Purpose:   t.Purpose,
+		ExpiresAt: t.ExpiresAt,
+	}
+
+	if err := r.db.Create(&model).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *userTokenRepo) GetUserToken(purpose, tokenHash string) (*user.UserToken, error) {
+	var model userTokenModel
+
+	if err := r.db.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, clients.ErrRecordNotFound
+		}
+
+		return nil, clients.ErrDB(err)
+	}
+
+	userID, err := uuid.Parse(model.UserID)
+	if err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	return &user.UserToken{
+		UserID:     userID,
+		Purpose:    model.Purpose,
+		ExpiresAt:  model.ExpiresAt,
+		ConsumedAt: model.ConsumedAt,
+	}, nil
+}
+
+func (r *userTokenRepo) ConsumeUserToken(purpose, tokenHash string) error {
+	now := time.Now()
+
+	if err := r.db.Model(&userTokenModel{}).
+		Where("token_hash = ? AND purpose = ?", tokenHash, purpose).
+		Update("consumed_at", now).Error; err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
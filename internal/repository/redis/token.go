@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"todo-app/pkg/clients"
+	"todo-app/user"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type tokenRepo struct {
+	client *redis.Client
+}
+
+func NewTokenRepo(client *redis.Client) *tokenRepo {
+	return &tokenRepo{
+		client: client,
+	}
+}
+
+func refreshTokenKey(tokenHash string) string {
+	return "refresh_token:" + tokenHash
+}
+
+func userSessionsKey(userID uuid.UUID) string {
+	return "user_sessions:" + userID.String()
+}
+
+func userRevokedAtKey(userID uuid.UUID) string {
+	return "user_revoked_at:" + userID.String()
+}
+
+// userRevokedAtTTL is how long a revocation marker needs to outlive the
+// access JWTs it protects against; it must cover the longest access-token
+// expiry configured anywhere the tokenprovider issues tokens.
+const userRevokedAtTTL = 24 * time.Hour
+
+func (r *tokenRepo) SaveRefreshToken(tokenHash string, meta *user.RefreshTokenMeta) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	ttl := time.Until(meta.ExpiresAt)
+	if ttl <= 0 {
+		return clients.ErrInternal(nil)
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, refreshTokenKey(tokenHash), data, ttl)
+	pipe.SAdd(ctx, userSessionsKey(meta.UserID), tokenHash)
+	pipe.Expire(ctx, userSessionsKey(meta.UserID), ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *tokenRepo) GetRefreshToken(tokenHash string) (*user.RefreshTokenMeta, error) {
+	ctx := context.Background()
+
+	data, err := r.client.Get(ctx, refreshTokenKey(tokenHash)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, clients.ErrRecordNotFound
+		}
+
+		return nil, clients.ErrDB(err)
+	}
+
+	var meta user.RefreshTokenMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	return &meta, nil
+}
+
+func (r *tokenRepo) RevokeRefreshToken(tokenHash string) error {
+	ctx := context.Background()
+
+	if err := r.client.Del(ctx, refreshTokenKey(tokenHash)).Err(); err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+func (r *tokenRepo) RevokeAllForUser(userID uuid.UUID) error {
+	ctx := context.Background()
+
+	hashes, err := r.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return clients.ErrDB(err)
+	}
+
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(hashes))
+	for i, h := range hashes {
+		keys[i] = refreshTokenKey(h)
+	}
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return clients.ErrDB(err)
+	}
+
+	if err := r.client.Del(ctx, userSessionsKey(userID)).Err(); err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+// MarkUserRevoked stamps the current time as the user's revocation point, so
+// any access JWT with an earlier IssuedAt claim can be rejected even before
+// it expires.
+func (r *tokenRepo) MarkUserRevoked(userID uuid.UUID) error {
+	ctx := context.Background()
+
+	if err := r.client.Set(ctx, userRevokedAtKey(userID), time.Now().UnixNano(), userRevokedAtTTL).Err(); err != nil {
+		return clients.ErrDB(err)
+	}
+
+	return nil
+}
+
+// UserRevokedAt returns the last time MarkUserRevoked was called for userID,
+// or the zero time if it never was (or the marker has expired).
+func (r *tokenRepo) UserRevokedAt(userID uuid.UUID) (time.Time, error) {
+	ctx := context.Background()
+
+	nanos, err := r.client.Get(ctx, userRevokedAtKey(userID)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, clients.ErrDB(err)
+	}
+
+	return time.Unix(0, nanos), nil
+}
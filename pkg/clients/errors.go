@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"errors"
+	"net/http"
+)
+
+// AppError is the single error shape returned to API clients, wrapping the
+// root cause for logs while keeping the client-facing message generic.
+type AppError struct {
+	StatusCode int    `json:"status_code"`
+	RootErr    error  `json:"-"`
+	Message    string `json:"message"`
+	Log        string `json:"log"`
+	Key        string `json:"error_key"`
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.RootErr
+}
+
+func NewAppError(statusCode int, rootErr error, message, key string) *AppError {
+	log := ""
+	if rootErr != nil {
+		log = rootErr.Error()
+	}
+
+	return &AppError{
+		StatusCode: statusCode,
+		RootErr:    rootErr,
+		Message:    message,
+		Log:        log,
+		Key:        key,
+	}
+}
+
+// ErrRecordNotFound is returned by repositories when a lookup finds nothing,
+// so callers can branch on errors.Is instead of comparing error strings.
+var ErrRecordNotFound = errors.New("record not found")
+
+func ErrDB(err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, err, "something went wrong with database", "DB_ERROR")
+}
+
+func ErrInvalidRequest(err error) *AppError {
+	return NewAppError(http.StatusBadRequest, err, "invalid request", "ERR_INVALID_REQUEST")
+}
+
+func ErrInternal(err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, err, "something went wrong internally", "ERR_INTERNAL")
+}
+
+func ErrCannotCreateEntity(entity string, err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, err, "cannot create "+entity, "ERR_CANNOT_CREATE_ENTITY")
+}
+
+func ErrCannotListEntity(entity string, err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, err, "cannot list "+entity, "ERR_CANNOT_LIST_ENTITY")
+}
+
+func ErrCannotGetEntity(entity string, err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, err, "cannot get "+entity, "ERR_CANNOT_GET_ENTITY")
+}
+
+func ErrCannotUpdateEntity(entity string, err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, err, "cannot update "+entity, "ERR_CANNOT_UPDATE_ENTITY")
+}
+
+func ErrCannotDeleteEntity(entity string, err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, err, "cannot delete "+entity, "ERR_CANNOT_DELETE_ENTITY")
+}
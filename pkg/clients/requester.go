@@ -0,0 +1,45 @@
+package clients
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CurrentUser is the gin.Context key middlewareAuth stores the authenticated
+// Requester under.
+const CurrentUser = "current_user"
+
+// Requester is what handlers pull out of the gin context to learn who's
+// making the request, without caring whether it came from a JWT or a
+// mocked test double.
+type Requester interface {
+	GetUserID() uuid.UUID
+	GetRole() string
+	GetPermissions() []string
+}
+
+// TokenPayload is the claim set embedded in the access JWT. ExpiresAt and
+// IssuedAt are populated from the JWT's standard exp/iat claims on Validate,
+// regardless of whether the caller set them before Generate. Permissions is
+// a snapshot of the role's grants at issuance time, embedded so authz.Require
+// doesn't need a DB/cache hit on every request.
+type TokenPayload struct {
+	UID         uuid.UUID `json:"uid"`
+	URole       string    `json:"u_role"`
+	Permissions []string  `json:"permissions"`
+	ExpiresAt   time.Time `json:"exp"`
+	IssuedAt    time.Time `json:"iat"`
+}
+
+func (p *TokenPayload) GetUserID() uuid.UUID {
+	return p.UID
+}
+
+func (p *TokenPayload) GetRole() string {
+	return p.URole
+}
+
+func (p *TokenPayload) GetPermissions() []string {
+	return p.Permissions
+}
@@ -0,0 +1,12 @@
+package clients
+
+// SuccessRes is the envelope every successful API response is wrapped in.
+type SuccessRes struct {
+	Data   any `json:"data"`
+	Filter any `json:"filter,omitempty"`
+	Paging any `json:"paging,omitempty"`
+}
+
+func SimpleSuccessResponse(data any) *SuccessRes {
+	return &SuccessRes{Data: data}
+}
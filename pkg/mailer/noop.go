@@ -0,0 +1,12 @@
+package mailer
+
+// noopMailer discards every message. It's wired in for tests so the
+// verification/reset flows can be exercised without a real SMTP server.
+type noopMailer struct{}
+
+func NewNoopMailer() *noopMailer {
+	return &noopMailer{}
+}
+
+func (*noopMailer) SendVerificationEmail(to, rawToken string) error  { return nil }
+func (*noopMailer) SendPasswordResetEmail(to, rawToken string) error { return nil }
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+)
+
+// smtpMailer sends verification and password-reset emails over SMTP using
+// plain auth, configured from the same kind of host/port/credentials any
+// transactional-mail provider's SMTP relay accepts.
+type smtpMailer struct {
+	addr      string
+	auth      smtp.Auth
+	from      string
+	verifyURL string
+	resetURL  string
+}
+
+// NewSMTPMailer builds a Mailer that sends through the SMTP server at
+// host:port. verifyURLFmt and resetURLFmt are fmt.Sprintf templates with a
+// single %s placeholder for the raw token.
+func NewSMTPMailer(host, port, username, password, from, verifyURLFmt, resetURLFmt string) *smtpMailer {
+	return &smtpMailer{
+		addr:      host + ":" + port,
+		auth:      smtp.PlainAuth("", username, password, host),
+		from:      from,
+		verifyURL: verifyURLFmt,
+		resetURL:  resetURLFmt,
+	}
+}
+
+func (m *smtpMailer) SendVerificationEmail(to, rawToken string) error {
+	return m.send(to, "Verify your account", fmt.Sprintf(m.verifyURL, rawToken))
+}
+
+func (m *smtpMailer) SendPasswordResetEmail(to, rawToken string) error {
+	return m.send(to, "Reset your password", fmt.Sprintf(m.resetURL, rawToken))
+}
+
+func (m *smtpMailer) send(to, subject, link string) error {
+	body := fmt.Sprintf("Subject: %s\r\n\r\n%s\r\n", subject, link)
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(body))
+}
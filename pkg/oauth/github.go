@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"todo-app/user"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const (
+	githubUserURL       = "https://api.github.com/user"
+	githubUserEmailsURL = "https://api.github.com/user/emails"
+)
+
+type githubProvider struct {
+	config *oauth2.Config
+}
+
+func NewGithubProvider(clientID, clientSecret, redirectURL string) *githubProvider {
+	return &githubProvider{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		},
+	}
+}
+
+func (p *githubProvider) Name() string { return "github" }
+
+func (p *githubProvider) AuthCodeURL(state string) string {
+	return p.config.AuthCodeURL(state)
+}
+
+func (p *githubProvider) ExchangeCode(code string) (user.UserInfoFields, error) {
+	ctx := context.Background()
+
+	token, err := p.config.Exchange(ctx, code)
+	if err != nil {
+		return user.UserInfoFields{}, err
+	}
+
+	client := p.config.Client(ctx, token)
+
+	var profile struct {
+		ID     int64  `json:"id"`
+		Name   string `json:"name"`
+		Email  string `json:"email"`
+		Avatar string `json:"avatar_url"`
+	}
+
+	if err := getJSON(client, githubUserURL, &profile); err != nil {
+		return user.UserInfoFields{}, err
+	}
+
+	email, verified := profile.Email, profile.Email != ""
+	if email == "" {
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+
+		if err := getJSON(client, githubUserEmailsURL, &emails); err != nil {
+			return user.UserInfoFields{}, err
+		}
+
+		for _, e := range emails {
+			if e.Primary {
+				email, verified = e.Email, e.Verified
+
+				break
+			}
+		}
+	}
+
+	if email == "" {
+		return user.UserInfoFields{}, errors.New("github: no verified email available")
+	}
+
+	return user.UserInfoFields{
+		Sub:           strconv.FormatInt(profile.ID, 10),
+		Email:         email,
+		EmailVerified: verified,
+		Name:          profile.Name,
+		Picture:       profile.Avatar,
+	}, nil
+}
+
+func getJSON(client *http.Client, url string, out any) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("github: unexpected status fetching " + url)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, out)
+}
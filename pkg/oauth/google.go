@@ -0,0 +1,81 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"todo-app/user"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+type googleProvider struct {
+	config *oauth2.Config
+}
+
+func NewGoogleProvider(clientID, clientSecret, redirectURL string) *googleProvider {
+	return &googleProvider{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"openid", "email", "profile"},
+			Endpoint:     google.Endpoint,
+		},
+	}
+}
+
+func (p *googleProvider) Name() string { return "google" }
+
+func (p *googleProvider) AuthCodeURL(state string) string {
+	return p.config.AuthCodeURL(state)
+}
+
+func (p *googleProvider) ExchangeCode(code string) (user.UserInfoFields, error) {
+	ctx := context.Background()
+
+	token, err := p.config.Exchange(ctx, code)
+	if err != nil {
+		return user.UserInfoFields{}, err
+	}
+
+	resp, err := p.config.Client(ctx, token).Get(googleUserInfoURL)
+	if err != nil {
+		return user.UserInfoFields{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user.UserInfoFields{}, errors.New("google: failed to fetch userinfo")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return user.UserInfoFields{}, err
+	}
+
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+
+	if err := json.Unmarshal(body, &info); err != nil {
+		return user.UserInfoFields{}, err
+	}
+
+	return user.UserInfoFields{
+		Sub:           info.Sub,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Name:          info.Name,
+		Picture:       info.Picture,
+	}, nil
+}
@@ -0,0 +1,23 @@
+package tokenprovider
+
+import "todo-app/pkg/clients"
+
+// Token is the generated access (or MFA-challenge) JWT. String returns the
+// raw compact token so it can be sent to clients or embedded in other
+// tokens.
+type Token interface {
+	String() string
+}
+
+// Provider generates and validates the JWTs issued at login/refresh time.
+type Provider interface {
+	Generate(payload *clients.TokenPayload, expiry int) (Token, error)
+	Validate(token string) (*clients.TokenPayload, error)
+}
+
+// TokenPair is what Login/RefreshToken return: a short-lived access JWT plus
+// a long-lived opaque refresh token.
+type TokenPair struct {
+	Access  Token  `json:"access_token"`
+	Refresh string `json:"refresh_token"`
+}
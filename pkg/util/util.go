@@ -0,0 +1,18 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// GenSalt returns a URL-safe random string at least n bytes of entropy long.
+// It backs password salts, refresh tokens, recovery codes and other
+// single-use secrets across the codebase.
+func GenSalt(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,51 @@
+package user
+
+import "time"
+
+const (
+	defaultPage  = 1
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Paging carries both offset-based and keyset (cursor) pagination state.
+// Mode selects which one GetAll uses; Limit applies to both, Page only to
+// offset paging, and Cursor/NextCursor carry the opaque (created_at,id)
+// keyset for cursor paging.
+type Paging struct {
+	Mode       string `form:"mode" json:"-"`
+	Page       int    `form:"page" json:"page,omitempty"`
+	Limit      int    `form:"limit" json:"limit"`
+	Cursor     string `form:"cursor" json:"-"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// IsCursorMode reports whether keyset pagination was requested.
+func (p *Paging) IsCursorMode() bool {
+	return p.Mode == "cursor"
+}
+
+// Process fills in sane defaults and enforces the max page size.
+func (p *Paging) Process() {
+	if p.Page <= 0 {
+		p.Page = defaultPage
+	}
+
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+}
+
+// UserFilter narrows GetAll's result set. Zero-value fields are ignored.
+type UserFilter struct {
+	Email         string     `form:"email"`
+	Role          int        `form:"role"`
+	CreatedAtFrom *time.Time `form:"created_at_from" time_format:"2006-01-02"`
+	CreatedAtTo   *time.Time `form:"created_at_to" time_format:"2006-01-02"`
+	Q             string     `form:"q"`
+}
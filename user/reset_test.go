@@ -0,0 +1,243 @@
+package user
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/pkg/clients"
+
+	"github.com/google/uuid"
+)
+
+type fakeUserRepoForReset struct {
+	user    *domain.User
+	getErr  error
+	updates []*domain.UserUpdate
+}
+
+func (r *fakeUserRepoForReset) Save(*domain.UserCreate) error { return nil }
+
+func (r *fakeUserRepoForReset) GetUser(map[string]any) (*domain.User, error) {
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+
+	return r.user, nil
+}
+
+func (r *fakeUserRepoForReset) GetAll(UserFilter, *Paging) ([]domain.User, error) { return nil, nil }
+
+func (r *fakeUserRepoForReset) Update(id uuid.UUID, update *domain.UserUpdate) error {
+	r.updates = append(r.updates, update)
+	return nil
+}
+
+func (r *fakeUserRepoForReset) UpdateFields(uuid.UUID, map[string]any) error { return nil }
+
+func (r *fakeUserRepoForReset) Delete(uuid.UUID) error { return nil }
+
+type fakeUserTokenRepo struct {
+	tokens  map[string]*UserToken
+	saveErr error
+}
+
+func newFakeUserTokenRepo() *fakeUserTokenRepo {
+	return &fakeUserTokenRepo{tokens: map[string]*UserToken{}}
+}
+
+func (r *fakeUserTokenRepo) SaveUserToken(tokenHash string, t *UserToken) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+
+	r.tokens[tokenHash] = t
+
+	return nil
+}
+
+func (r *fakeUserTokenRepo) GetUserToken(purpose, tokenHash string) (*UserToken, error) {
+	t, ok := r.tokens[tokenHash]
+	if !ok || t.Purpose != purpose {
+		return nil, clients.ErrRecordNotFound
+	}
+
+	return t, nil
+}
+
+func (r *fakeUserTokenRepo) ConsumeUserToken(purpose, tokenHash string) error {
+	t, ok := r.tokens[tokenHash]
+	if !ok || t.Purpose != purpose {
+		return clients.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	t.ConsumedAt = &now
+
+	return nil
+}
+
+type fakeMailer struct {
+	verificationSent []string
+	resetSent        []string
+	sendErr          error
+}
+
+func (m *fakeMailer) SendVerificationEmail(to, rawToken string) error {
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+
+	m.verificationSent = append(m.verificationSent, to)
+
+	return nil
+}
+
+func (m *fakeMailer) SendPasswordResetEmail(to, rawToken string) error {
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+
+	m.resetSent = append(m.resetSent, to)
+
+	return nil
+}
+
+func newResetTestService(userRepo *fakeUserRepoForReset, userTokenRepo *fakeUserTokenRepo, tokenRepo *fakeTokenRepo, mailer *fakeMailer) *userService {
+	return &userService{
+		userRepo:        userRepo,
+		userTokenRepo:   userTokenRepo,
+		tokenRepo:       tokenRepo,
+		hasher:          fakeHasher{},
+		mailer:          mailer,
+		userTokenExpiry: 3600,
+	}
+}
+
+func TestRequestEmailVerificationSwallowsNotFound(t *testing.T) {
+	userRepo := &fakeUserRepoForReset{getErr: clients.ErrRecordNotFound}
+	mailer := &fakeMailer{}
+	svc := newResetTestService(userRepo, newFakeUserTokenRepo(), newFakeTokenRepo(), mailer)
+
+	if err := svc.RequestEmailVerification("nobody@example.com"); err != nil {
+		t.Fatalf("RequestEmailVerification returned %v, want nil so account existence isn't leaked", err)
+	}
+
+	if len(mailer.verificationSent) != 0 {
+		t.Error("a verification email was sent for an email address with no account")
+	}
+}
+
+func TestRequestEmailVerificationPropagatesOtherErrors(t *testing.T) {
+	dbErr := errors.New("db unavailable")
+	userRepo := &fakeUserRepoForReset{getErr: dbErr}
+	svc := newResetTestService(userRepo, newFakeUserTokenRepo(), newFakeTokenRepo(), &fakeMailer{})
+
+	if err := svc.RequestEmailVerification("someone@example.com"); !errors.Is(err, dbErr) {
+		t.Errorf("RequestEmailVerification err = %v, want %v to propagate instead of being swallowed", err, dbErr)
+	}
+}
+
+func TestRequestPasswordResetSwallowsNotFound(t *testing.T) {
+	userRepo := &fakeUserRepoForReset{getErr: clients.ErrRecordNotFound}
+	mailer := &fakeMailer{}
+	svc := newResetTestService(userRepo, newFakeUserTokenRepo(), newFakeTokenRepo(), mailer)
+
+	if err := svc.RequestPasswordReset("nobody@example.com"); err != nil {
+		t.Fatalf("RequestPasswordReset returned %v, want nil so account existence isn't leaked", err)
+	}
+
+	if len(mailer.resetSent) != 0 {
+		t.Error("a password reset email was sent for an email address with no account")
+	}
+}
+
+func TestRequestPasswordResetPropagatesOtherErrors(t *testing.T) {
+	dbErr := errors.New("db unavailable")
+	userRepo := &fakeUserRepoForReset{getErr: dbErr}
+	svc := newResetTestService(userRepo, newFakeUserTokenRepo(), newFakeTokenRepo(), &fakeMailer{})
+
+	if err := svc.RequestPasswordReset("someone@example.com"); !errors.Is(err, dbErr) {
+		t.Errorf("RequestPasswordReset err = %v, want %v to propagate instead of being swallowed", err, dbErr)
+	}
+}
+
+func TestConfirmPasswordResetRejectsUnknownToken(t *testing.T) {
+	svc := newResetTestService(&fakeUserRepoForReset{}, newFakeUserTokenRepo(), newFakeTokenRepo(), &fakeMailer{})
+
+	if err := svc.ConfirmPasswordReset("never-issued", "newpassword"); err != domain.ErrTokenInvalid {
+		t.Errorf("err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+}
+
+func TestConfirmPasswordResetRejectsExpiredToken(t *testing.T) {
+	userTokenRepo := newFakeUserTokenRepo()
+	svc := newResetTestService(&fakeUserRepoForReset{}, userTokenRepo, newFakeTokenRepo(), &fakeMailer{})
+
+	rawToken := "reset-raw"
+	tokenHash := svc.hasher.Hash(rawToken)
+	userTokenRepo.tokens[tokenHash] = &UserToken{
+		UserID:    uuid.New(),
+		Purpose:   TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+
+	if err := svc.ConfirmPasswordReset(rawToken, "newpassword"); err != domain.ErrTokenInvalid {
+		t.Errorf("err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+}
+
+func TestConfirmPasswordResetRejectsAlreadyConsumedToken(t *testing.T) {
+	userTokenRepo := newFakeUserTokenRepo()
+	svc := newResetTestService(&fakeUserRepoForReset{}, userTokenRepo, newFakeTokenRepo(), &fakeMailer{})
+
+	rawToken := "reset-raw"
+	tokenHash := svc.hasher.Hash(rawToken)
+	consumedAt := time.Now().Add(-time.Minute)
+	userTokenRepo.tokens[tokenHash] = &UserToken{
+		UserID:     uuid.New(),
+		Purpose:    TokenPurposePasswordReset,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		ConsumedAt: &consumedAt,
+	}
+
+	if err := svc.ConfirmPasswordReset(rawToken, "newpassword"); err != domain.ErrTokenInvalid {
+		t.Errorf("err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+}
+
+func TestConfirmPasswordResetRevokesExistingSessions(t *testing.T) {
+	userID := uuid.New()
+	userTokenRepo := newFakeUserTokenRepo()
+	tokenRepo := newFakeTokenRepo()
+	userRepo := &fakeUserRepoForReset{}
+	svc := newResetTestService(userRepo, userTokenRepo, tokenRepo, &fakeMailer{})
+
+	rawToken := "reset-raw"
+	tokenHash := svc.hasher.Hash(rawToken)
+	userTokenRepo.tokens[tokenHash] = &UserToken{
+		UserID:    userID,
+		Purpose:   TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	tokenRepo.refreshTokens["other-session"] = &RefreshTokenMeta{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	if err := svc.ConfirmPasswordReset(rawToken, "newpassword"); err != nil {
+		t.Fatalf("ConfirmPasswordReset returned error: %v", err)
+	}
+
+	if len(userRepo.updates) != 1 {
+		t.Fatalf("Update called %d times, want 1", len(userRepo.updates))
+	}
+
+	if !tokenRepo.refreshTokens["other-session"].Revoked {
+		t.Error("other sessions' refresh tokens were not revoked")
+	}
+
+	if tokenRepo.revokedUsers[userID].IsZero() {
+		t.Error("ConfirmPasswordReset did not mark the user revoked, so a still-valid access JWT would survive it")
+	}
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"errors"
+	"time"
 	"todo-app/domain"
 	"todo-app/pkg/clients"
 	"todo-app/pkg/tokenprovider"
@@ -13,29 +14,300 @@ import (
 type UserRepo interface {
 	Save(user *domain.UserCreate) error
 	GetUser(conditions map[string]any) (*domain.User, error)
-	GetAll() ([]domain.User, error)
+	GetAll(filter UserFilter, paging *Paging) ([]domain.User, error)
 	Update(id uuid.UUID, user *domain.UserUpdate) error
+	// UpdateFields applies fields as a map rather than a struct, so zero
+	// values (false, "") are persisted instead of silently skipped the way
+	// GORM's struct-mode Updates skips them.
+	UpdateFields(id uuid.UUID, fields map[string]any) error
 	Delete(id uuid.UUID) error
 }
 
+// TokenRepo persists refresh-token sessions so they can be looked up, rotated
+// and revoked independently of the short-lived access JWT.
+type TokenRepo interface {
+	SaveRefreshToken(tokenHash string, meta *RefreshTokenMeta) error
+	GetRefreshToken(tokenHash string) (*RefreshTokenMeta, error)
+	RevokeRefreshToken(tokenHash string) error
+	RevokeAllForUser(userID uuid.UUID) error
+
+	// MarkUserRevoked records that every access JWT issued for userID before
+	// now should stop being honoured, so middlewareAuth's near-expiry check
+	// can catch logout/password-reset/role-change even while the JWT itself
+	// hasn't expired yet.
+	MarkUserRevoked(userID uuid.UUID) error
+	// UserRevokedAt returns the last time MarkUserRevoked was called for
+	// userID, or the zero time if it never was.
+	UserRevokedAt(userID uuid.UUID) (time.Time, error)
+}
+
+// RefreshTokenMeta is the session metadata stored alongside a hashed refresh
+// token so logout and forced revocation can invalidate it before it expires.
+type RefreshTokenMeta struct {
+	UserID    uuid.UUID
+	Role      string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	Revoked   bool
+}
+
+// UserInfoFields is the normalized profile an OIDC provider returns after a
+// successful code exchange.
+type UserInfoFields struct {
+	Sub           string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// LoginProvider is implemented once per external identity provider (Google,
+// GitHub, ...) so userService can treat social login as an alternative to the
+// password flow without knowing provider-specific OAuth2/OIDC details.
+type LoginProvider interface {
+	Name() string
+	AuthCodeURL(state string) string
+	ExchangeCode(code string) (UserInfoFields, error)
+}
+
+// IdentityRepo links one or more external provider identities to a
+// domain.User so the same account can be reached via password login or any
+// linked provider.
+type IdentityRepo interface {
+	GetIdentity(provider, subject string) (*domain.UserIdentity, error)
+	SaveIdentity(identity *domain.UserIdentity) error
+	DeleteIdentity(userID uuid.UUID, provider string) error
+}
+
 type Hasher interface {
 	Hash(data string) string
 }
 
+// Mailer dispatches the emails the verification and password-reset flows
+// depend on. The SMTP implementation is used in production; a no-op
+// implementation is used in tests.
+type Mailer interface {
+	SendVerificationEmail(to, rawToken string) error
+	SendPasswordResetEmail(to, rawToken string) error
+}
+
+// PermissionProvider looks up the permissions granted to a user across their
+// RBAC roles, so they can be embedded directly in the JWT at issuance time
+// instead of re-querying the database on every request.
+type PermissionProvider interface {
+	PermissionsForUser(userID uuid.UUID) ([]string, error)
+}
+
+// UserTokenRepo persists single-use, time-limited tokens (email verification,
+// password reset) hashed at rest, keyed by purpose so the same table can
+// back more than one flow.
+type UserTokenRepo interface {
+	SaveUserToken(tokenHash string, t *UserToken) error
+	GetUserToken(purpose, tokenHash string) (*UserToken, error)
+	ConsumeUserToken(purpose, tokenHash string) error
+}
+
+// UserToken is a row in user_tokens: a single-use token scoped to a purpose
+// ("verify_email", "password_reset") and a user.
+type UserToken struct {
+	UserID     uuid.UUID
+	Purpose    string
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+}
+
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposePasswordReset = "password_reset"
+)
+
+// MFAChallengeRole marks a short-lived access token as an MFA challenge
+// rather than a real session, so LoginTOTP can tell the two apart and
+// middlewareAuth must reject it everywhere else.
+const MFAChallengeRole = "mfa_challenge"
+
 type userService struct {
-	userRepo      UserRepo
-	hasher        Hasher
-	tokenProvider tokenprovider.Provider
-	expiry        int
+	userRepo             UserRepo
+	tokenRepo            TokenRepo
+	identityRepo         IdentityRepo
+	userTokenRepo        UserTokenRepo
+	recoveryCodeRepo     RecoveryCodeRepo
+	permissionProvider   PermissionProvider
+	hasher               Hasher
+	tokenProvider        tokenprovider.Provider
+	totpProvider         TOTPProvider
+	mailer               Mailer
+	providers            map[string]LoginProvider
+	expiry               int
+	refreshExpiry        int
+	userTokenExpiry      int
+	mfaChallengeExpiry   int
+	requireEmailVerified bool
 }
 
-func NewUserService(repo UserRepo, hasher Hasher, tokenProvider tokenprovider.Provider, expiry int) *userService {
+func NewUserService(
+	repo UserRepo,
+	tokenRepo TokenRepo,
+	identityRepo IdentityRepo,
+	userTokenRepo UserTokenRepo,
+	recoveryCodeRepo RecoveryCodeRepo,
+	permissionProvider PermissionProvider,
+	hasher Hasher,
+	tokenProvider tokenprovider.Provider,
+	totpProvider TOTPProvider,
+	mailer Mailer,
+	providers []LoginProvider,
+	expiry int,
+	refreshExpiry int,
+	userTokenExpiry int,
+	mfaChallengeExpiry int,
+	requireEmailVerified bool,
+) *userService {
+	byName := make(map[string]LoginProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
 	return &userService{
-		userRepo:      repo,
-		hasher:        hasher,
-		tokenProvider: tokenProvider,
-		expiry:        expiry,
+		userRepo:             repo,
+		tokenRepo:            tokenRepo,
+		identityRepo:         identityRepo,
+		userTokenRepo:        userTokenRepo,
+		recoveryCodeRepo:     recoveryCodeRepo,
+		permissionProvider:   permissionProvider,
+		hasher:               hasher,
+		tokenProvider:        tokenProvider,
+		totpProvider:         totpProvider,
+		mailer:               mailer,
+		providers:            byName,
+		expiry:               expiry,
+		refreshExpiry:        refreshExpiry,
+		userTokenExpiry:      userTokenExpiry,
+		mfaChallengeExpiry:   mfaChallengeExpiry,
+		requireEmailVerified: requireEmailVerified,
+	}
+}
+
+// issueUserToken generates a single-use raw token, stores only its hash and
+// returns the raw value so the caller can email it.
+func (s *userService) issueUserToken(userID uuid.UUID, purpose string) (string, error) {
+	rawToken := util.GenSalt(40)
+	tokenHash := s.hasher.Hash(rawToken)
+
+	t := &UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(time.Duration(s.userTokenExpiry) * time.Second),
+	}
+
+	if err := s.userTokenRepo.SaveUserToken(tokenHash, t); err != nil {
+		return "", clients.ErrInternal(err)
+	}
+
+	return rawToken, nil
+}
+
+// consumeUserToken validates a raw token against its hash, rejecting it if
+// it's expired or already used, and marks it consumed.
+func (s *userService) consumeUserToken(purpose, rawToken string) (*UserToken, error) {
+	tokenHash := s.hasher.Hash(rawToken)
+
+	t, err := s.userTokenRepo.GetUserToken(purpose, tokenHash)
+	if err != nil {
+		return nil, domain.ErrTokenInvalid
+	}
+
+	if t.ConsumedAt != nil || time.Now().After(t.ExpiresAt) {
+		return nil, domain.ErrTokenInvalid
+	}
+
+	if err := s.userTokenRepo.ConsumeUserToken(purpose, tokenHash); err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	return t, nil
+}
+
+func (s *userService) loginProvider(name string) (LoginProvider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, clients.ErrInvalidRequest(errors.New("unsupported oauth provider: " + name))
+	}
+
+	return provider, nil
+}
+
+// findOrCreateFromOAuth maps a provider's UserInfoFields to an existing
+// domain.User by email, or creates one with a random salt and no password
+// hash so it can only be reached through a linked identity.
+func (s *userService) findOrCreateFromOAuth(info UserInfoFields) (*domain.User, error) {
+	if !info.EmailVerified {
+		return nil, domain.ErrOAuthEmailNotVerified
+	}
+
+	user, err := s.userRepo.GetUser(map[string]any{"email": info.Email})
+	if err != nil {
+		if !errors.Is(err, clients.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		data := &domain.UserCreate{
+			ID:            uuid.New(),
+			Email:         info.Email,
+			Password:      "",
+			Salt:          util.GenSalt(50),
+			Role:          1,
+			EmailVerified: true,
+		}
+
+		if err := s.userRepo.Save(data); err != nil {
+			return nil, clients.ErrCannotCreateEntity(data.TableName(), err)
+		}
+
+		user, err = s.userRepo.GetUser(map[string]any{"id": data.ID})
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	return user, nil
+}
+
+// issueTokenPair generates a fresh access token plus a refresh token, storing
+// only the refresh token's hash so a leaked DB/cache snapshot can't be
+// replayed directly.
+func (s *userService) issueTokenPair(user *domain.User) (tokenprovider.TokenPair, error) {
+	permissions, err := s.permissionProvider.PermissionsForUser(user.ID)
+	if err != nil {
+		return tokenprovider.TokenPair{}, clients.ErrInternal(err)
+	}
+
+	payload := &clients.TokenPayload{
+		UID:         user.ID,
+		URole:       user.Role.String(),
+		Permissions: permissions,
+	}
+
+	accessToken, err := s.tokenProvider.Generate(payload, s.expiry)
+	if err != nil {
+		return tokenprovider.TokenPair{}, clients.ErrInternal(err)
+	}
+
+	rawRefresh := util.GenSalt(64)
+	refreshHash := s.hasher.Hash(rawRefresh)
+
+	meta := &RefreshTokenMeta{
+		UserID:    user.ID,
+		Role:      user.Role.String(),
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Duration(s.refreshExpiry) * time.Second),
+	}
+
+	if err := s.tokenRepo.SaveRefreshToken(refreshHash, meta); err != nil {
+		return tokenprovider.TokenPair{}, clients.ErrInternal(err)
+	}
+
+	return tokenprovider.TokenPair{Access: accessToken, Refresh: rawRefresh}, nil
 }
 
 func (s *userService) Register(data *domain.UserCreate) error {
@@ -60,41 +332,311 @@ func (s *userService) Register(data *domain.UserCreate) error {
 	data.Password = s.hasher.Hash(data.Password + salt)
 	data.Salt = salt
 	data.Role = 1
+	data.EmailVerified = false
 
 	if err := s.userRepo.Save(data); err != nil {
 		return clients.ErrCannotCreateEntity(data.TableName(), err)
 	}
 
+	rawToken, err := s.issueUserToken(data.ID, TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.SendVerificationEmail(data.Email, rawToken); err != nil {
+		return clients.ErrInternal(err)
+	}
+
 	return nil
 }
 
-func (s *userService) Login(data *domain.UserLogin) (tokenprovider.Token, error) {
+// LoginResult is what Login returns: either a real token pair, or an
+// mfa_required challenge that must be completed via LoginTOTP.
+type LoginResult struct {
+	MFARequired    bool                    `json:"mfa_required"`
+	ChallengeToken tokenprovider.Token     `json:"challenge_token,omitempty"`
+	Tokens         tokenprovider.TokenPair `json:"tokens,omitempty"`
+}
+
+func (s *userService) Login(data *domain.UserLogin) (LoginResult, error) {
 	user, err := s.userRepo.GetUser(map[string]interface{}{"email": data.Email})
 	if err != nil {
-		return nil, domain.ErrEmailOrPasswordInvalid
+		return LoginResult{}, domain.ErrEmailOrPasswordInvalid
 	}
 
 	passHashed := s.hasher.Hash(data.Password + user.Salt)
 
 	if user.Password != passHashed {
-		return nil, domain.ErrEmailOrPasswordInvalid
+		return LoginResult{}, domain.ErrEmailOrPasswordInvalid
 	}
 
-	payload := &clients.TokenPayload{
-		UID:   user.ID,
-		URole: user.Role.String(),
+	if s.requireEmailVerified && !user.EmailVerified {
+		return LoginResult{}, domain.ErrEmailNotVerified
 	}
 
-	accessToken, err := s.tokenProvider.Generate(payload, s.expiry)
+	return s.completeLogin(user)
+}
+
+// completeLogin issues a real token pair for user, or - if they have TOTP
+// enabled - an mfa_required challenge that must be completed via LoginTOTP
+// instead. Every path that can authenticate a user as a full session
+// (password login, OAuth login) must go through this so 2FA can't be
+// bypassed by using a different login method.
+func (s *userService) completeLogin(user *domain.User) (LoginResult, error) {
+	if user.TOTPEnabled {
+		challengePayload := &clients.TokenPayload{UID: user.ID, URole: MFAChallengeRole}
+
+		challengeToken, err := s.tokenProvider.Generate(challengePayload, s.mfaChallengeExpiry)
+		if err != nil {
+			return LoginResult{}, clients.ErrInternal(err)
+		}
+
+		return LoginResult{MFARequired: true, ChallengeToken: challengeToken}, nil
+	}
+
+	tokens, err := s.issueTokenPair(user)
 	if err != nil {
-		return nil, clients.ErrInternal(err)
+		return LoginResult{}, err
+	}
+
+	return LoginResult{Tokens: tokens}, nil
+}
+
+// RequestEmailVerification issues a fresh verification token for the given
+// email and sends it, regardless of whether the account is already verified.
+func (s *userService) RequestEmailVerification(email string) error {
+	user, err := s.userRepo.GetUser(map[string]any{"email": email})
+	if err != nil {
+		if errors.Is(err, clients.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	rawToken, err := s.issueUserToken(user.ID, TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.SendVerificationEmail(user.Email, rawToken); err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	return nil
+}
+
+// VerifyEmail consumes a verification token and marks the owning account
+// verified.
+func (s *userService) VerifyEmail(rawToken string) error {
+	t, err := s.consumeUserToken(TokenPurposeVerifyEmail, rawToken)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	update := &domain.UserUpdate{EmailVerified: true, VerifiedAt: &now}
+
+	if err := s.userRepo.Update(t.UserID, update); err != nil {
+		return clients.ErrCannotUpdateEntity(update.TableName(), err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset issues a password reset token and emails it. It never
+// reports whether the email exists, to avoid leaking account existence.
+func (s *userService) RequestPasswordReset(email string) error {
+	user, err := s.userRepo.GetUser(map[string]any{"email": email})
+	if err != nil {
+		if errors.Is(err, clients.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	rawToken, err := s.issueUserToken(user.ID, TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.SendPasswordResetEmail(user.Email, rawToken); err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	return nil
+}
+
+// ConfirmPasswordReset consumes a password reset token, re-salts and stores
+// the new password, and invalidates every existing refresh token for the
+// user so other sessions can't keep using the old credentials.
+func (s *userService) ConfirmPasswordReset(rawToken, newPassword string) error {
+	t, err := s.consumeUserToken(TokenPurposePasswordReset, rawToken)
+	if err != nil {
+		return err
+	}
+
+	salt := util.GenSalt(50)
+	update := &domain.UserUpdate{
+		Password: s.hasher.Hash(newPassword + salt),
+		Salt:     salt,
+	}
+
+	if err := s.userRepo.Update(t.UserID, update); err != nil {
+		return clients.ErrCannotUpdateEntity(update.TableName(), err)
+	}
+
+	if err := s.tokenRepo.RevokeAllForUser(t.UserID); err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	if err := s.tokenRepo.MarkUserRevoked(t.UserID); err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	return nil
+}
+
+// RefreshToken validates a raw refresh token against the store, rotates it
+// (invalidating the prior one so a replayed token is rejected) and returns a
+// fresh access+refresh pair.
+func (s *userService) RefreshToken(rawRefresh string) (tokenprovider.TokenPair, error) {
+	tokenHash := s.hasher.Hash(rawRefresh)
+
+	meta, err := s.tokenRepo.GetRefreshToken(tokenHash)
+	if err != nil {
+		return tokenprovider.TokenPair{}, domain.ErrRefreshTokenInvalid
+	}
+
+	if meta.Revoked || time.Now().After(meta.ExpiresAt) {
+		return tokenprovider.TokenPair{}, domain.ErrRefreshTokenInvalid
+	}
+
+	if err := s.tokenRepo.RevokeRefreshToken(tokenHash); err != nil {
+		return tokenprovider.TokenPair{}, clients.ErrInternal(err)
+	}
+
+	user, err := s.userRepo.GetUser(map[string]any{"id": meta.UserID})
+	if err != nil {
+		return tokenprovider.TokenPair{}, domain.ErrRefreshTokenInvalid
+	}
+
+	return s.issueTokenPair(user)
+}
+
+// Logout revokes the refresh token so the session can no longer be used to
+// mint new access tokens, even while the current JWT is still valid.
+func (s *userService) Logout(rawRefresh string) error {
+	tokenHash := s.hasher.Hash(rawRefresh)
+
+	meta, err := s.tokenRepo.GetRefreshToken(tokenHash)
+	if err != nil && !errors.Is(err, clients.ErrRecordNotFound) {
+		return clients.ErrInternal(err)
+	}
+
+	if err := s.tokenRepo.RevokeRefreshToken(tokenHash); err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	if meta != nil {
+		if err := s.tokenRepo.MarkUserRevoked(meta.UserID); err != nil {
+			return clients.ErrInternal(err)
+		}
 	}
 
-	return accessToken, nil
+	return nil
 }
 
-func (s *userService) GetAllUser() ([]domain.User, error) {
-	users, err := s.userRepo.GetAll()
+// OAuthAuthCodeURL returns the provider's authorization URL the handler
+// should redirect the browser to.
+func (s *userService) OAuthAuthCodeURL(providerName, state string) (string, error) {
+	provider, err := s.loginProvider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	return provider.AuthCodeURL(state), nil
+}
+
+// OAuthLogin exchanges an authorization code for the provider's user info,
+// maps it to a domain.User (creating one if needed) and issues the same
+// token pair the password login returns.
+func (s *userService) OAuthLogin(providerName, code string) (LoginResult, error) {
+	provider, err := s.loginProvider(providerName)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	info, err := provider.ExchangeCode(code)
+	if err != nil {
+		return LoginResult{}, clients.ErrInvalidRequest(err)
+	}
+
+	user, err := s.findOrCreateFromOAuth(info)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	identity, err := s.identityRepo.GetIdentity(providerName, info.Sub)
+	if err != nil && !errors.Is(err, clients.ErrRecordNotFound) {
+		return LoginResult{}, err
+	}
+
+	if identity == nil {
+		identity = &domain.UserIdentity{
+			Provider: providerName,
+			Subject:  info.Sub,
+			UserID:   user.ID,
+		}
+
+		if err := s.identityRepo.SaveIdentity(identity); err != nil {
+			return LoginResult{}, clients.ErrCannotCreateEntity("user_identities", err)
+		}
+	}
+
+	return s.completeLogin(user)
+}
+
+// LinkIdentity attaches an external provider identity to an already
+// authenticated user, allowing the same account to log in via that provider.
+func (s *userService) LinkIdentity(userID uuid.UUID, providerName, code string) error {
+	provider, err := s.loginProvider(providerName)
+	if err != nil {
+		return err
+	}
+
+	info, err := provider.ExchangeCode(code)
+	if err != nil {
+		return clients.ErrInvalidRequest(err)
+	}
+
+	identity := &domain.UserIdentity{
+		Provider: providerName,
+		Subject:  info.Sub,
+		UserID:   userID,
+	}
+
+	if err := s.identityRepo.SaveIdentity(identity); err != nil {
+		return clients.ErrCannotCreateEntity("user_identities", err)
+	}
+
+	return nil
+}
+
+// UnlinkIdentity removes a linked provider identity from a user.
+func (s *userService) UnlinkIdentity(userID uuid.UUID, providerName string) error {
+	if err := s.identityRepo.DeleteIdentity(userID, providerName); err != nil {
+		return clients.ErrCannotDeleteEntity("user_identities", err)
+	}
+
+	return nil
+}
+
+func (s *userService) GetAllUser(filter UserFilter, paging *Paging) ([]domain.User, error) {
+	paging.Process()
+
+	users, err := s.userRepo.GetAll(filter, paging)
 	if err != nil {
 		return nil, clients.ErrCannotListEntity(domain.User{}.TableName(), err)
 	}
@@ -0,0 +1,198 @@
+package user
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-app/domain"
+	"todo-app/pkg/clients"
+	"todo-app/pkg/tokenprovider"
+
+	"github.com/google/uuid"
+)
+
+type fakeToken string
+
+func (t fakeToken) String() string { return string(t) }
+
+type fakeTokenProvider struct{}
+
+func (fakeTokenProvider) Generate(payload *clients.TokenPayload, expiry int) (tokenprovider.Token, error) {
+	return fakeToken("token:" + payload.UID.String()), nil
+}
+
+func (fakeTokenProvider) Validate(token string) (*clients.TokenPayload, error) {
+	return nil, errors.New("not implemented")
+}
+
+type fakePermissionProvider struct{}
+
+func (fakePermissionProvider) PermissionsForUser(userID uuid.UUID) ([]string, error) {
+	return nil, nil
+}
+
+type fakeUserRepoForToken struct {
+	user domain.User
+}
+
+func (r *fakeUserRepoForToken) Save(*domain.UserCreate) error { return nil }
+
+func (r *fakeUserRepoForToken) GetUser(map[string]any) (*domain.User, error) {
+	u := r.user
+	return &u, nil
+}
+
+func (r *fakeUserRepoForToken) GetAll(UserFilter, *Paging) ([]domain.User, error) { return nil, nil }
+
+func (r *fakeUserRepoForToken) Update(uuid.UUID, *domain.UserUpdate) error { return nil }
+
+func (r *fakeUserRepoForToken) UpdateFields(uuid.UUID, map[string]any) error { return nil }
+
+func (r *fakeUserRepoForToken) Delete(uuid.UUID) error { return nil }
+
+type fakeTokenRepo struct {
+	refreshTokens map[string]*RefreshTokenMeta
+	revokedUsers  map[uuid.UUID]time.Time
+}
+
+func newFakeTokenRepo() *fakeTokenRepo {
+	return &fakeTokenRepo{
+		refreshTokens: map[string]*RefreshTokenMeta{},
+		revokedUsers:  map[uuid.UUID]time.Time{},
+	}
+}
+
+func (r *fakeTokenRepo) SaveRefreshToken(tokenHash string, meta *RefreshTokenMeta) error {
+	r.refreshTokens[tokenHash] = meta
+	return nil
+}
+
+func (r *fakeTokenRepo) GetRefreshToken(tokenHash string) (*RefreshTokenMeta, error) {
+	meta, ok := r.refreshTokens[tokenHash]
+	if !ok {
+		return nil, clients.ErrRecordNotFound
+	}
+
+	return meta, nil
+}
+
+// RevokeRefreshToken mirrors the redis implementation's delete-is-idempotent
+// behavior: revoking a token that was never issued (or already revoked) is
+// not an error.
+func (r *fakeTokenRepo) RevokeRefreshToken(tokenHash string) error {
+	if meta, ok := r.refreshTokens[tokenHash]; ok {
+		meta.Revoked = true
+	}
+
+	return nil
+}
+
+func (r *fakeTokenRepo) RevokeAllForUser(userID uuid.UUID) error {
+	for _, meta := range r.refreshTokens {
+		if meta.UserID == userID {
+			meta.Revoked = true
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeTokenRepo) MarkUserRevoked(userID uuid.UUID) error {
+	r.revokedUsers[userID] = time.Now()
+	return nil
+}
+
+func (r *fakeTokenRepo) UserRevokedAt(userID uuid.UUID) (time.Time, error) {
+	return r.revokedUsers[userID], nil
+}
+
+func newTokenTestService(userRepo *fakeUserRepoForToken, tokenRepo *fakeTokenRepo) *userService {
+	return &userService{
+		userRepo:           userRepo,
+		tokenRepo:          tokenRepo,
+		permissionProvider: fakePermissionProvider{},
+		hasher:             fakeHasher{},
+		tokenProvider:      fakeTokenProvider{},
+		refreshExpiry:      3600,
+	}
+}
+
+func TestRefreshTokenRotatesAndRejectsReplay(t *testing.T) {
+	userID := uuid.New()
+	userRepo := &fakeUserRepoForToken{user: domain.User{ID: userID}}
+	tokenRepo := newFakeTokenRepo()
+	svc := newTokenTestService(userRepo, tokenRepo)
+
+	rawRefresh := "refresh-raw"
+	tokenHash := svc.hasher.Hash(rawRefresh)
+	tokenRepo.refreshTokens[tokenHash] = &RefreshTokenMeta{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	if _, err := svc.RefreshToken(rawRefresh); err != nil {
+		t.Fatalf("RefreshToken returned error: %v", err)
+	}
+
+	if !tokenRepo.refreshTokens[tokenHash].Revoked {
+		t.Error("original refresh token was not revoked after use")
+	}
+
+	if _, err := svc.RefreshToken(rawRefresh); err != domain.ErrRefreshTokenInvalid {
+		t.Errorf("replayed RefreshToken err = %v, want %v", err, domain.ErrRefreshTokenInvalid)
+	}
+}
+
+func TestRefreshTokenRejectsExpired(t *testing.T) {
+	userID := uuid.New()
+	userRepo := &fakeUserRepoForToken{user: domain.User{ID: userID}}
+	tokenRepo := newFakeTokenRepo()
+	svc := newTokenTestService(userRepo, tokenRepo)
+
+	rawRefresh := "refresh-raw"
+	tokenHash := svc.hasher.Hash(rawRefresh)
+	tokenRepo.refreshTokens[tokenHash] = &RefreshTokenMeta{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+
+	if _, err := svc.RefreshToken(rawRefresh); err != domain.ErrRefreshTokenInvalid {
+		t.Errorf("err = %v, want %v", err, domain.ErrRefreshTokenInvalid)
+	}
+}
+
+func TestLogoutRevokesRefreshTokenAndUser(t *testing.T) {
+	userID := uuid.New()
+	userRepo := &fakeUserRepoForToken{user: domain.User{ID: userID}}
+	tokenRepo := newFakeTokenRepo()
+	svc := newTokenTestService(userRepo, tokenRepo)
+
+	rawRefresh := "refresh-raw"
+	tokenHash := svc.hasher.Hash(rawRefresh)
+	tokenRepo.refreshTokens[tokenHash] = &RefreshTokenMeta{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	if err := svc.Logout(rawRefresh); err != nil {
+		t.Fatalf("Logout returned error: %v", err)
+	}
+
+	if !tokenRepo.refreshTokens[tokenHash].Revoked {
+		t.Error("refresh token was not revoked by Logout")
+	}
+
+	if tokenRepo.revokedUsers[userID].IsZero() {
+		t.Error("Logout did not mark the user revoked, so a still-valid access JWT would survive it")
+	}
+}
+
+func TestLogoutToleratesUnknownRefreshToken(t *testing.T) {
+	userRepo := &fakeUserRepoForToken{}
+	tokenRepo := newFakeTokenRepo()
+	svc := newTokenTestService(userRepo, tokenRepo)
+
+	if err := svc.Logout("never-issued"); err != nil {
+		t.Fatalf("Logout returned error for an unknown token, want nil: %v", err)
+	}
+}
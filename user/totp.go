@@ -0,0 +1,188 @@
+package user
+
+import (
+	"todo-app/domain"
+	"todo-app/pkg/clients"
+	"todo-app/pkg/tokenprovider"
+	"todo-app/pkg/util"
+
+	"github.com/google/uuid"
+)
+
+// TOTPProvider generates and validates RFC 6238 TOTP secrets/codes and
+// renders the QR code shown during enrollment.
+type TOTPProvider interface {
+	GenerateSecret(accountName string) (secret string, otpauthURL string, err error)
+	GenerateQRPNG(otpauthURL string) ([]byte, error)
+	Validate(code, secret string) bool
+	Encrypt(secret string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// RecoveryCodeRepo persists the one-time recovery codes issued alongside TOTP
+// enrollment, each consumable exactly once.
+type RecoveryCodeRepo interface {
+	SaveRecoveryCodes(userID uuid.UUID, hashedCodes []string) error
+	ConsumeRecoveryCode(userID uuid.UUID, hashedCode string) error
+	DeleteRecoveryCodes(userID uuid.UUID) error
+}
+
+const recoveryCodeCount = 10
+
+// generateRecoveryCodes returns recoveryCodeCount fresh raw codes alongside
+// their hashes, ready to be shown once to the user and stored respectively.
+func (s *userService) generateRecoveryCodes() (raw []string, hashed []string) {
+	raw = make([]string, recoveryCodeCount)
+	hashed = make([]string, recoveryCodeCount)
+
+	for i := range raw {
+		raw[i] = util.GenSalt(10)
+		hashed[i] = s.hasher.Hash(raw[i])
+	}
+
+	return raw, hashed
+}
+
+// EnrollTOTP generates a new TOTP secret for the user and returns it along
+// with the otpauth:// URL and a QR code PNG. TOTPEnabled is only flipped once
+// VerifyTOTP confirms the user can produce a valid code.
+func (s *userService) EnrollTOTP(userID uuid.UUID) (secret string, otpauthURL string, qrPNG []byte, err error) {
+	user, err := s.userRepo.GetUser(map[string]any{"id": userID})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	secret, otpauthURL, err = s.totpProvider.GenerateSecret(user.Email)
+	if err != nil {
+		return "", "", nil, clients.ErrInternal(err)
+	}
+
+	encrypted, err := s.totpProvider.Encrypt(secret)
+	if err != nil {
+		return "", "", nil, clients.ErrInternal(err)
+	}
+
+	if err := s.userRepo.Update(userID, &domain.UserUpdate{TOTPSecret: encrypted}); err != nil {
+		return "", "", nil, clients.ErrCannotUpdateEntity(domain.User{}.TableName(), err)
+	}
+
+	qrPNG, err = s.totpProvider.GenerateQRPNG(otpauthURL)
+	if err != nil {
+		return "", "", nil, clients.ErrInternal(err)
+	}
+
+	return secret, otpauthURL, qrPNG, nil
+}
+
+// VerifyTOTP confirms the user holds a working authenticator by validating a
+// code against the secret generated during EnrollTOTP, then flips
+// TOTPEnabled and issues a fresh batch of recovery codes.
+func (s *userService) VerifyTOTP(userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetUser(map[string]any{"id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.totpProvider.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	if !s.totpProvider.Validate(code, secret) {
+		return nil, domain.ErrTOTPCodeInvalid
+	}
+
+	if err := s.userRepo.Update(userID, &domain.UserUpdate{TOTPEnabled: true}); err != nil {
+		return nil, clients.ErrCannotUpdateEntity(domain.User{}.TableName(), err)
+	}
+
+	rawCodes, hashedCodes := s.generateRecoveryCodes()
+	if err := s.recoveryCodeRepo.SaveRecoveryCodes(userID, hashedCodes); err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	return rawCodes, nil
+}
+
+// DisableTOTP turns two-factor auth off for the user after confirming a
+// valid code, and drops any remaining recovery codes.
+func (s *userService) DisableTOTP(userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetUser(map[string]any{"id": userID})
+	if err != nil {
+		return err
+	}
+
+	secret, err := s.totpProvider.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return clients.ErrInternal(err)
+	}
+
+	if !s.totpProvider.Validate(code, secret) {
+		return domain.ErrTOTPCodeInvalid
+	}
+
+	// A zero-valued domain.UserUpdate wouldn't persist here: GORM's struct-mode
+	// Updates skips false/"" fields, so disabling 2FA needs the map form to
+	// actually clear totp_enabled and totp_secret.
+	fields := map[string]any{"totp_enabled": false, "totp_secret": ""}
+	if err := s.userRepo.UpdateFields(userID, fields); err != nil {
+		return clients.ErrCannotUpdateEntity(domain.User{}.TableName(), err)
+	}
+
+	return s.recoveryCodeRepo.DeleteRecoveryCodes(userID)
+}
+
+// RegenerateRecoveryCodes invalidates any remaining recovery codes and issues
+// a fresh batch, after confirming a valid TOTP code.
+func (s *userService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetUser(map[string]any{"id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.totpProvider.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	if !s.totpProvider.Validate(code, secret) {
+		return nil, domain.ErrTOTPCodeInvalid
+	}
+
+	rawCodes, hashedCodes := s.generateRecoveryCodes()
+	if err := s.recoveryCodeRepo.SaveRecoveryCodes(userID, hashedCodes); err != nil {
+		return nil, clients.ErrInternal(err)
+	}
+
+	return rawCodes, nil
+}
+
+// LoginTOTP completes a login that was interrupted by an mfa_required
+// challenge: it accepts the challenge token plus either a live TOTP code or a
+// one-use recovery code, and issues the real access+refresh pair.
+func (s *userService) LoginTOTP(challengeToken, code string) (tokenprovider.TokenPair, error) {
+	payload, err := s.tokenProvider.Validate(challengeToken)
+	if err != nil || payload.URole != MFAChallengeRole {
+		return tokenprovider.TokenPair{}, domain.ErrMFAChallengeInvalid
+	}
+
+	user, err := s.userRepo.GetUser(map[string]any{"id": payload.UID})
+	if err != nil {
+		return tokenprovider.TokenPair{}, domain.ErrMFAChallengeInvalid
+	}
+
+	secret, err := s.totpProvider.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return tokenprovider.TokenPair{}, clients.ErrInternal(err)
+	}
+
+	if s.totpProvider.Validate(code, secret) {
+		return s.issueTokenPair(user)
+	}
+
+	if err := s.recoveryCodeRepo.ConsumeRecoveryCode(user.ID, s.hasher.Hash(code)); err == nil {
+		return s.issueTokenPair(user)
+	}
+
+	return tokenprovider.TokenPair{}, domain.ErrTOTPCodeInvalid
+}
@@ -0,0 +1,147 @@
+package user
+
+import (
+	"strings"
+	"testing"
+	"todo-app/domain"
+
+	"github.com/google/uuid"
+)
+
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(data string) string { return "hashed:" + data }
+
+type fakeTOTPProvider struct {
+	validCode string
+}
+
+func (fakeTOTPProvider) GenerateSecret(accountName string) (string, string, error) {
+	return "secret", "otpauth://totp/" + accountName, nil
+}
+
+func (fakeTOTPProvider) GenerateQRPNG(otpauthURL string) ([]byte, error) { return nil, nil }
+
+func (p fakeTOTPProvider) Validate(code, secret string) bool { return code == p.validCode }
+
+func (fakeTOTPProvider) Encrypt(secret string) (string, error) { return "enc:" + secret, nil }
+
+func (fakeTOTPProvider) Decrypt(ciphertext string) (string, error) {
+	return strings.TrimPrefix(ciphertext, "enc:"), nil
+}
+
+type fakeUserRepoForTOTP struct {
+	user         domain.User
+	updateFields map[string]any
+	updateCalls  int
+}
+
+func (r *fakeUserRepoForTOTP) Save(*domain.UserCreate) error { return nil }
+
+func (r *fakeUserRepoForTOTP) GetUser(map[string]any) (*domain.User, error) {
+	u := r.user
+	return &u, nil
+}
+
+func (r *fakeUserRepoForTOTP) GetAll(UserFilter, *Paging) ([]domain.User, error) { return nil, nil }
+
+func (r *fakeUserRepoForTOTP) Update(id uuid.UUID, update *domain.UserUpdate) error { return nil }
+
+func (r *fakeUserRepoForTOTP) UpdateFields(id uuid.UUID, fields map[string]any) error {
+	r.updateCalls++
+	r.updateFields = fields
+
+	return nil
+}
+
+func (r *fakeUserRepoForTOTP) Delete(uuid.UUID) error { return nil }
+
+type fakeRecoveryCodeRepo struct {
+	saved   []string
+	deleted bool
+}
+
+func (r *fakeRecoveryCodeRepo) SaveRecoveryCodes(userID uuid.UUID, hashedCodes []string) error {
+	r.saved = hashedCodes
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) ConsumeRecoveryCode(userID uuid.UUID, hashedCode string) error {
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) DeleteRecoveryCodes(userID uuid.UUID) error {
+	r.deleted = true
+	return nil
+}
+
+func newTOTPTestService(userRepo *fakeUserRepoForTOTP, recoveryRepo *fakeRecoveryCodeRepo) *userService {
+	return &userService{
+		userRepo:         userRepo,
+		hasher:           fakeHasher{},
+		totpProvider:     fakeTOTPProvider{validCode: "123456"},
+		recoveryCodeRepo: recoveryRepo,
+	}
+}
+
+func TestGenerateRecoveryCodesCountAndHashes(t *testing.T) {
+	svc := newTOTPTestService(&fakeUserRepoForTOTP{}, &fakeRecoveryCodeRepo{})
+
+	raw, hashed := svc.generateRecoveryCodes()
+
+	if len(raw) != recoveryCodeCount || len(hashed) != recoveryCodeCount {
+		t.Fatalf("got %d raw / %d hashed codes, want %d each", len(raw), len(hashed), recoveryCodeCount)
+	}
+
+	seen := make(map[string]bool, len(raw))
+	for i, r := range raw {
+		if seen[r] {
+			t.Errorf("raw code %q generated more than once", r)
+		}
+		seen[r] = true
+
+		if hashed[i] != "hashed:"+r {
+			t.Errorf("hashed[%d] = %q, want %q", i, hashed[i], "hashed:"+r)
+		}
+	}
+}
+
+func TestDisableTOTPClearsFieldsViaMap(t *testing.T) {
+	userRepo := &fakeUserRepoForTOTP{user: domain.User{ID: uuid.New(), TOTPSecret: "enc:secret"}}
+	recoveryRepo := &fakeRecoveryCodeRepo{}
+	svc := newTOTPTestService(userRepo, recoveryRepo)
+
+	if err := svc.DisableTOTP(userRepo.user.ID, "123456"); err != nil {
+		t.Fatalf("DisableTOTP returned error: %v", err)
+	}
+
+	if userRepo.updateCalls != 1 {
+		t.Fatalf("UpdateFields called %d times, want 1", userRepo.updateCalls)
+	}
+
+	if enabled, ok := userRepo.updateFields["totp_enabled"].(bool); !ok || enabled {
+		t.Errorf("totp_enabled = %v, want false", userRepo.updateFields["totp_enabled"])
+	}
+
+	if secret, ok := userRepo.updateFields["totp_secret"].(string); !ok || secret != "" {
+		t.Errorf("totp_secret = %v, want empty string", userRepo.updateFields["totp_secret"])
+	}
+
+	if !recoveryRepo.deleted {
+		t.Error("recovery codes were not deleted")
+	}
+}
+
+func TestDisableTOTPRejectsInvalidCode(t *testing.T) {
+	userRepo := &fakeUserRepoForTOTP{user: domain.User{ID: uuid.New(), TOTPSecret: "enc:secret"}}
+	svc := newTOTPTestService(userRepo, &fakeRecoveryCodeRepo{})
+
+	err := svc.DisableTOTP(userRepo.user.ID, "000000")
+	if err != domain.ErrTOTPCodeInvalid {
+		t.Errorf("err = %v, want %v", err, domain.ErrTOTPCodeInvalid)
+	}
+
+	if userRepo.updateCalls != 0 {
+		t.Errorf("UpdateFields called %d times, want 0 for a rejected code", userRepo.updateCalls)
+	}
+}